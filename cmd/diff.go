@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/infragov-project/infrarun/pkg/infrarun/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+	"github.com/spf13/cobra"
+)
+
+// Labels of the baseline states in the text output of the diff command
+var diffStateLabels = map[string]string{
+	report.BaselineNew:       "new",
+	report.BaselineAbsent:    "fixed",
+	report.BaselineUpdated:   "updated",
+	report.BaselineUnchanged: "unchanged",
+}
+
+func writeDiffText(w io.Writer, diff *report.Diff) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	for _, summary := range diff.Summary {
+		fmt.Fprintf(tw, "%s %s: %d new, %d fixed, %d updated, %d unchanged\n",
+			summary.Tool, summary.Rule, summary.New, summary.Fixed, summary.Updated, summary.Unchanged)
+
+		for _, finding := range diff.Findings {
+			if finding.Tool != summary.Tool || finding.Rule != summary.Rule {
+				continue
+			}
+
+			location := finding.URI
+
+			if finding.Line > 0 {
+				location += ":" + strconv.Itoa(finding.Line)
+			}
+
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", diffStateLabels[finding.State], location, finding.Message)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func writeDiff(w io.Writer, diff *report.Diff, format string) error {
+	switch format {
+	case "text":
+		return writeDiffText(w, diff)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(diff)
+	case "sarif":
+		return report.WriteSARIF(w, diff.Report)
+	default:
+		return fmt.Errorf("unknown diff format: %s", format)
+	}
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	format, err := cmd.Flags().GetString("format")
+
+	if err != nil {
+		panic(err)
+	}
+
+	output, err := cmd.Flags().GetString("output")
+
+	if err != nil {
+		panic(err)
+	}
+
+	before, err := sarif.Open(args[0])
+
+	if err != nil {
+		panic(err)
+	}
+
+	after, err := sarif.Open(args[1])
+
+	if err != nil {
+		panic(err)
+	}
+
+	diff, err := report.Compare(before, after)
+
+	if err != nil {
+		panic(err)
+	}
+
+	var w io.Writer = os.Stdout
+
+	if output != "" {
+		file, err := os.Create(output)
+
+		if err != nil {
+			panic(err)
+		}
+
+		defer file.Close()
+
+		w = file
+	}
+
+	err = writeDiff(w, diff, format)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff BEFORE AFTER",
+	Short: "Compare two reports",
+	Long: `Compares two SARIF reports produced by infrarun, matching their findings by fingerprint, and lists
+the new, fixed, updated and unchanged findings of each rule of each tool.`,
+	Args: cobra.ExactArgs(2),
+	Run:  runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringP("format", "f", "text", "output format: text, json or sarif (the after report with the baselineState of its results)")
+	diffCmd.Flags().StringP("output", "o", "", "file to write the comparison to, instead of the standard output")
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"github.com/infragov-project/infrarun/internal/core/config"
+	"github.com/infragov-project/infrarun/pkg/infrarun/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+	"github.com/spf13/cobra"
+)
+
+func runMerge(cmd *cobra.Command, args []string) {
+	root, err := cmd.Flags().GetString("root")
+
+	if err != nil {
+		panic(err)
+	}
+
+	cfg, err := loadConfig(cmd, root)
+
+	if err != nil {
+		panic(err)
+	}
+
+	mode, equivalences, err := dedupFromFlags(cmd, cfg)
+
+	if err != nil {
+		panic(err)
+	}
+
+	filters, err := filtersFromFlags(cmd, cfg)
+
+	if err != nil {
+		panic(err)
+	}
+
+	reports := make([]*sarif.Report, 0, len(args))
+
+	for _, path := range args {
+		rep, err := report.Open(path)
+
+		if err != nil {
+			panic(err)
+		}
+
+		reports = append(reports, rep)
+	}
+
+	merged, err := report.Merge(reports, report.MergeOptions{
+		Root:         root,
+		Dedup:        mode,
+		Equivalences: equivalences,
+		Taxonomy:     report.DefaultTaxonomy().Extend(cfg.Taxonomy),
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	report.ApplyFilters(merged, filters...)
+
+	err = writeOutputs(cmd, merged, root)
+
+	if err != nil {
+		panic(err)
+	}
+
+	err = printSummary(cmd, merged)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge REPORT...",
+	Short: "Merge SARIF reports",
+	Long: `Merges SARIF reports, produced by infrarun or by other tools run on the same files (such as CodeQL), into a
+single report. Reports are validated against the SARIF 2.1.0 schema. File locations are made relative to the
+analyzed root, and results are fingerprinted, classified and deduplicated as in the reports of the run command.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runMerge,
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+
+	addOutputFlags(mergeCmd)
+	mergeCmd.Flags().String("root", ".", "path analyzed by the tools that produced the reports")
+	mergeCmd.Flags().StringP("config", "c", "", "project configuration file (default: "+config.FileName+" in the root, if present)")
+	mergeCmd.Flags().String("dedup", "off", "deduplicate results found at the same place by equivalent rules: off, report or collapse")
+
+	addFilterFlags(mergeCmd)
+	addSummaryFlags(mergeCmd)
+}
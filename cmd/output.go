@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/infragov-project/infrarun/internal/core/engine"
+	"github.com/infragov-project/infrarun/pkg/infrarun/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+	"github.com/spf13/cobra"
+)
+
+// outputSink is a file, or the standard output if it has no path, that the report is written to in a format.
+type outputSink struct {
+	Format string
+	Path   string
+}
+
+// parseOutputSink parses FORMAT[=PATH]. Anything else than the name of a format, such as report.sarif or merged,
+// is the path of a SARIF report.
+func parseOutputSink(spec string) (outputSink, error) {
+	format, path, hasPath := strings.Cut(spec, "=")
+
+	if !slices.Contains(report.OutputFormats(), format) {
+		if hasPath {
+			return outputSink{}, fmt.Errorf("unknown output format: %s (available: %s)", format, strings.Join(report.OutputFormats(), ", "))
+		}
+
+		return outputSink{Format: "sarif", Path: spec}, nil
+	}
+
+	if hasPath && path == "" {
+		return outputSink{}, fmt.Errorf("empty path for output format %s", format)
+	}
+
+	return outputSink{Format: format, Path: path}, nil
+}
+
+func writeOutput(rep *sarif.Report, sink outputSink, opts report.WriteOptions) error {
+	var w io.Writer = os.Stdout
+
+	if sink.Path != "" {
+		file, err := os.Create(sink.Path)
+
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		w = file
+	}
+
+	return report.Write(w, rep, sink.Format, opts)
+}
+
+// pathPrefix returns the path given by the path-prefix flag or, if there is none, the path of root relative to the
+// root of the git repository containing it.
+func pathPrefix(cmd *cobra.Command, root string) (string, error) {
+	if cmd.Flags().Changed("path-prefix") {
+		return cmd.Flags().GetString("path-prefix")
+	}
+
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return "", nil
+	}
+
+	return engine.GitPrefix(root), nil
+}
+
+// writeOutputs writes rep, a report of the files under root, to every output given by the output flag.
+func writeOutputs(cmd *cobra.Command, rep *sarif.Report, root string) error {
+	specs, err := cmd.Flags().GetStringArray("output")
+
+	if err != nil {
+		return err
+	}
+
+	title, err := cmd.Flags().GetString("title")
+
+	if err != nil {
+		return err
+	}
+
+	maxSize, err := cmd.Flags().GetInt("max-size")
+
+	if err != nil {
+		return err
+	}
+
+	linkBase, err := cmd.Flags().GetString("link-base")
+
+	if err != nil {
+		return err
+	}
+
+	perFinding, err := cmd.Flags().GetBool("junit-per-finding")
+
+	if err != nil {
+		return err
+	}
+
+	prefix, err := pathPrefix(cmd, root)
+
+	if err != nil {
+		return err
+	}
+
+	opts := report.WriteOptions{Title: title, MaxSize: maxSize, LinkBase: linkBase, PathPrefix: prefix, PerFinding: perFinding}
+
+	sinks := make([]outputSink, 0, len(specs))
+	toStdout := 0
+
+	for _, spec := range specs {
+		sink, err := parseOutputSink(spec)
+
+		if err != nil {
+			return err
+		}
+
+		if sink.Path == "" {
+			toStdout++
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	if toStdout > 1 {
+		return fmt.Errorf("only one output can go to the standard output")
+	}
+
+	for _, sink := range sinks {
+		err = writeOutput(rep, sink, opts)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addOutputFlags adds the flags selecting where and in which formats the report is written to cmd.
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayP("output", "o", []string{"sarif"}, "write the report as FORMAT[=PATH], to the standard output if there is no path, or as SARIF to a PATH alone; repeatable (formats: "+strings.Join(report.OutputFormats(), ", ")+")")
+	cmd.Flags().String("title", "", "title of the report, in the formats showing one")
+	cmd.Flags().Int("max-size", report.DefaultMarkdownMaxSize, "bytes that markdown reports are truncated to, to fit in pull request comments (-1 for no limit)")
+	cmd.Flags().Bool("junit-per-finding", false, "one failing test case per finding in JUnit reports, instead of one per rule and file")
+	cmd.Flags().String("link-base", "", "URL of the root of the repository at the analyzed revision, for the links of markdown reports (default: the repository of --ref, if hosted over http)")
+	cmd.Flags().String("path-prefix", "", "path of the analyzed path in its repository, prepended to file paths in gitlab and checkstyle reports and links (default: detected with git)")
+}
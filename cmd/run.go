@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/infragov-project/infrarun/internal/core/config"
 	"github.com/infragov-project/infrarun/pkg/infrarun/plan"
+	"github.com/infragov-project/infrarun/pkg/infrarun/report"
 	"github.com/infragov-project/infrarun/pkg/infrarun/run"
 	"github.com/infragov-project/infrarun/pkg/infrarun/tool"
 	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
@@ -46,6 +50,47 @@ func runRun(cmd *cobra.Command, args []string) {
 		panic(err)
 	}
 
+	policy, err := inputPolicyFromFlags(cmd)
+
+	if err != nil {
+		panic(err)
+	}
+
+	ref, err := cmd.Flags().GetString("ref")
+
+	if err != nil {
+		panic(err)
+	}
+
+	excludes, err := cmd.Flags().GetStringSlice("exclude")
+
+	if err != nil {
+		panic(err)
+	}
+
+	splitBy, err := cmd.Flags().GetString("split-by")
+
+	if err != nil {
+		panic(err)
+	}
+
+	var roots []string
+
+	// Projects are found once for every tool, since it may take exporting a git revision or unpacking an archive
+	if splitBy != "" {
+		strategy, err := plan.ParseSplitStrategy(splitBy)
+
+		if err != nil {
+			panic(err)
+		}
+
+		roots, err = plan.FindProjectRootsAt(path, ref, policy.Archive, strategy)
+
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	var p plan.Plan
 
 	for _, toolName := range args {
@@ -55,20 +100,74 @@ func runRun(cmd *cobra.Command, args []string) {
 			panic("tool not found: " + toolName)
 		}
 
-		run, err := plan.NewSimpleRun(path, &tool)
+		var runs []*plan.Run
 
-		if err != nil {
-			panic(err)
+		if splitBy == "" {
+			run, err := plan.NewSimpleRun(path, &tool)
+
+			if err != nil {
+				panic(err)
+			}
+
+			runs = append(runs, run)
+		} else {
+			runs, err = plan.NewRootRuns(path, roots, "**/*", &tool, nil)
+
+			if err != nil {
+				panic(err)
+			}
 		}
 
-		p.AddRun(run)
+		for _, run := range runs {
+			run.SetInputPolicy(policy)
+			run.SetExcludes(excludes...)
+			run.SetRef(ref)
+
+			p.AddRun(run)
+		}
+	}
+
+	cfg, err := loadConfig(cmd, path)
+
+	if err != nil {
+		panic(err)
+	}
+
+	opts, err := reportOptionsFromFlags(cmd, cfg)
+
+	if err != nil {
+		panic(err)
+	}
+
+	suppressions, err := loadSuppressions(cmd, path)
+
+	if err != nil {
+		panic(err)
+	}
+
+	for _, s := range report.ExpiredSuppressions(suppressions, time.Now().UTC()) {
+		fmt.Fprintf(os.Stderr, "warning: suppression of %s:%s owned by %s expired on %s\n", s.Tool, s.Rule, s.Owner, s.Expires.Format(time.DateOnly))
+	}
+
+	opts = append(opts, run.WithSuppressions(suppressions))
+
+	failOnNew, err := cmd.Flags().GetBool("fail-on-new")
+
+	if err != nil {
+		panic(err)
+	}
+
+	if failOnNew && !cmd.Flags().Changed("baseline") {
+		panic("--fail-on-new requires --baseline")
 	}
 
 	ctx := context.Background()
 
 	obs := newObserver(&p)
 
-	rep, err := run.Run(ctx, p, run.WithObserver(obs))
+	opts = append(opts, run.WithObserver(obs))
+
+	rep, err := run.Run(ctx, p, opts...)
 
 	if err != nil {
 		panic(err)
@@ -76,11 +175,330 @@ func runRun(cmd *cobra.Command, args []string) {
 
 	obs.progress.Wait()
 
-	err = rep.PrettyWrite(os.Stdout)
+	for _, r := range p.Runs {
+		for _, skipped := range r.SkippedFiles() {
+			fmt.Fprintf(os.Stderr, "%s: skipped %s: %s\n", runLabel(r), skipped.Path, skipped.Reason)
+		}
+	}
+
+	err = writeOutputs(cmd, rep, path)
 
 	if err != nil {
 		panic(err)
 	}
+
+	err = printSummary(cmd, rep)
+
+	if err != nil {
+		panic(err)
+	}
+
+	failed := report.FailedRuns(rep)
+
+	for _, f := range failed {
+		label := f.Tool
+
+		if f.AutomationID != "" {
+			label = f.AutomationID
+		}
+
+		fmt.Fprintf(os.Stderr, "%s failed: %s\n", label, strings.Join(f.Errors, "; "))
+	}
+
+	if len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d runs failed\n", len(failed), len(p.Runs))
+		os.Exit(1)
+	}
+
+	if failOnNew {
+		if n := report.CountByBaselineState(rep, report.BaselineNew); n > 0 {
+			fmt.Fprintf(os.Stderr, "%d new findings\n", n)
+			os.Exit(1)
+		}
+	}
+}
+
+// loadConfig reads the file given by the config flag or, if there is none, the configuration file found in path.
+func loadConfig(cmd *cobra.Command, path string) (*config.Config, error) {
+	configPath, err := cmd.Flags().GetString("config")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if configPath != "" {
+		return config.Load(configPath)
+	}
+
+	return config.Find(path)
+}
+
+// loadSuppressions reads the file given by the suppressions flag or, if there is none, the suppressions file found in path.
+func loadSuppressions(cmd *cobra.Command, path string) ([]report.ExternalSuppression, error) {
+	suppressionsPath, err := cmd.Flags().GetString("suppressions")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var file *config.Suppressions
+
+	if suppressionsPath != "" {
+		file, err = config.LoadSuppressions(suppressionsPath)
+	} else {
+		file, err = config.FindSuppressions(path)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	suppressions := make([]report.ExternalSuppression, 0, len(file.Suppressions))
+
+	for _, s := range file.Suppressions {
+		expires, err := s.ExpiryDate()
+
+		if err != nil {
+			return nil, err
+		}
+
+		suppressions = append(suppressions, report.ExternalSuppression{
+			Tool:        s.Tool,
+			Rule:        s.Rule,
+			Path:        s.Path,
+			Fingerprint: s.Fingerprint,
+			Owner:       s.Owner,
+			Reason:      s.Reason,
+			Expires:     expires,
+		})
+	}
+
+	return suppressions, nil
+}
+
+// reportOptionsFromFlags returns the options of the report processing steps enabled by the flags or the configuration.
+func reportOptionsFromFlags(cmd *cobra.Command, cfg *config.Config) ([]run.Option, error) {
+	dedup, err := dedupOptionFromFlags(cmd, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []run.Option{
+		dedup,
+		run.WithSeverityOverrides(cfg.Severity),
+		run.WithTaxonomy(report.DefaultTaxonomy().Extend(cfg.Taxonomy)),
+	}
+
+	snippetLines, err := cmd.Flags().GetInt("snippet-lines")
+
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, run.WithSnippets(snippetLines))
+
+	redact, err := cmd.Flags().GetBool("redact")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if redact {
+		opts = append(opts, run.WithRedaction())
+	}
+
+	filters, err := filtersFromFlags(cmd, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, run.WithFilters(filters...))
+
+	baselinePath, err := cmd.Flags().GetString("baseline")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if baselinePath != "" {
+		baseline, err := sarif.Open(baselinePath)
+
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, run.WithBaseline(baseline))
+	}
+
+	return opts, nil
+}
+
+func dedupOptionFromFlags(cmd *cobra.Command, cfg *config.Config) (run.Option, error) {
+	mode, equivalences, err := dedupFromFlags(cmd, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return run.WithDeduplication(mode, equivalences), nil
+}
+
+// dedupFromFlags returns the deduplication mode given by the dedup flag or the configuration, and the rule
+// equivalences of the configuration.
+func dedupFromFlags(cmd *cobra.Command, cfg *config.Config) (report.DedupMode, report.RuleEquivalences, error) {
+	mode := cfg.Dedup.Mode
+
+	if cmd.Flags().Changed("dedup") || mode == "" {
+		var err error
+		mode, err = cmd.Flags().GetString("dedup")
+
+		if err != nil {
+			return report.DedupOff, nil, err
+		}
+	}
+
+	dedupMode, err := report.ParseDedupMode(mode)
+
+	if err != nil {
+		return report.DedupOff, nil, err
+	}
+
+	equivalences, err := report.NewRuleEquivalences(cfg.Dedup.Rules)
+
+	if err != nil {
+		return report.DedupOff, nil, err
+	}
+
+	return dedupMode, equivalences, nil
+}
+
+// addFilterFlags adds the flags selecting the results kept in the report to cmd.
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("min-level", "", "keep only results with at least this level: none, note, warning or error")
+	cmd.Flags().StringSlice("rule", nil, "keep only results of these rules, as rule id globs or tool:rule")
+	cmd.Flags().StringSlice("exclude-rule", nil, "leave out results of these rules, as rule id globs or tool:rule")
+	cmd.Flags().StringSlice("tool", nil, "keep only results of these tools")
+	cmd.Flags().StringSlice("filter-path", nil, "keep only results in files matching these globs, relative to the analyzed path (the paths filter; --path names the analyzed path itself)")
+	cmd.Flags().StringSlice("category", nil, "keep only results in these taxonomy categories, or related to these CWE ids (as CWE-798)")
+}
+
+// filtersFromFlags returns the filters given by the flags or, for the flags not set, by the configuration.
+func filtersFromFlags(cmd *cobra.Command, cfg *config.Config) ([]report.Filter, error) {
+	settings := cfg.Filters
+
+	if cmd.Flags().Changed("min-level") {
+		minLevel, err := cmd.Flags().GetString("min-level")
+
+		if err != nil {
+			return nil, err
+		}
+
+		settings.MinLevel = minLevel
+	}
+
+	lists := map[string]*[]string{
+		"rule":         &settings.Rules,
+		"exclude-rule": &settings.ExcludeRules,
+		"tool":         &settings.Tools,
+		"filter-path":  &settings.Paths,
+		"category":     &settings.Categories,
+	}
+
+	for flag, setting := range lists {
+		if !cmd.Flags().Changed(flag) {
+			continue
+		}
+
+		values, err := cmd.Flags().GetStringSlice(flag)
+
+		if err != nil {
+			return nil, err
+		}
+
+		*setting = values
+	}
+
+	filters := make([]report.Filter, 0)
+
+	if settings.MinLevel != "" {
+		level, err := report.ParseLevel(settings.MinLevel)
+
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, report.MinLevel(level))
+	}
+
+	if len(settings.Tools) > 0 {
+		filters = append(filters, report.Tools(settings.Tools...))
+	}
+
+	if len(settings.Rules) > 0 {
+		filters = append(filters, report.Rules(settings.Rules...))
+	}
+
+	if len(settings.ExcludeRules) > 0 {
+		filters = append(filters, report.ExcludeRules(settings.ExcludeRules...))
+	}
+
+	if len(settings.Paths) > 0 {
+		filters = append(filters, report.Paths(settings.Paths...))
+	}
+
+	if len(settings.Categories) > 0 {
+		filters = append(filters, report.Categories(settings.Categories...))
+	}
+
+	return filters, nil
+}
+
+func runLabel(r *plan.Run) string {
+	if r.ProjectRoot() != "" {
+		return r.ToolName() + " (" + r.ProjectRoot() + ")"
+	}
+
+	return r.ToolName()
+}
+
+func inputPolicyFromFlags(cmd *cobra.Command) (plan.InputPolicy, error) {
+	policy := plan.DefaultInputPolicy()
+
+	symlinks, err := cmd.Flags().GetString("symlinks")
+
+	if err != nil {
+		return policy, err
+	}
+
+	policy.Symlinks, err = plan.ParseSymlinkPolicy(symlinks)
+
+	if err != nil {
+		return policy, err
+	}
+
+	policy.MaxFileSize, err = cmd.Flags().GetInt64("max-file-size")
+
+	if err != nil {
+		return policy, err
+	}
+
+	policy.SkipBinary, err = cmd.Flags().GetBool("skip-binary")
+
+	if err != nil {
+		return policy, err
+	}
+
+	policy.Archive.MaxEntries, err = cmd.Flags().GetInt("max-archive-entries")
+
+	if err != nil {
+		return policy, err
+	}
+
+	policy.Archive.MaxSize, err = cmd.Flags().GetInt64("max-archive-size")
+
+	return policy, err
 }
 
 type progressBarObserver struct {
@@ -105,7 +523,7 @@ func (o *progressBarObserver) OnEnginePreparation() {
 		o.bars[r] = o.progress.Add(
 			100,
 			filler{},
-			mpb.PrependDecorators(decor.Name(r.ToolName()+"\t")),
+			mpb.PrependDecorators(decor.Name(runLabel(r)+"\t")),
 			mpb.AppendDecorators(decor.Percentage()),
 		)
 		//o.bars[r] = o.progress.AddBar(100, mpb.PrependDecorators(decor.Name(r.ToolName())), mpb.AppendDecorators(decor.Percentage()))
@@ -200,5 +618,24 @@ var runCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(runCmd)
 
-	runCmd.Flags().StringP("path", "p", ".", "path to run the tools at")
+	runCmd.Flags().StringP("path", "p", ".", "path to run the tools at, either a directory or a .tar, .tar.gz, .tgz or .zip archive")
+	runCmd.Flags().StringP("config", "c", "", "project configuration file (default: "+config.FileName+" in the path, if present)")
+	runCmd.Flags().String("suppressions", "", "suppressions file (default: "+config.SuppressionsFileName+" in the path, if present)")
+	runCmd.Flags().String("dedup", "off", "deduplicate results found at the same place by equivalent rules: off, report or collapse")
+	runCmd.Flags().Int("snippet-lines", report.DefaultSnippetContextLines, "lines of code around each result included in the report (-1 for no snippets)")
+	runCmd.Flags().Bool("redact", false, "keep the analyzed code out of the report, removing every snippet and the code in GLITCH messages")
+	runCmd.Flags().String("baseline", "", "SARIF report of a previous run to compare the results with")
+	runCmd.Flags().Bool("fail-on-new", false, "exit with an error if the comparison with --baseline finds new results")
+	runCmd.Flags().String("split-by", "", "run each tool once per project found, in the analyzed revision or archive, by: terraform-root, ansible-role or directory-depth=N")
+	runCmd.Flags().String("ref", "", "git revision to analyze instead of the working copy")
+	runCmd.Flags().StringSlice("exclude", nil, "glob patterns of files to leave out, relative to the path")
+	runCmd.Flags().String("symlinks", "follow-within-root", "how to handle symbolic links: skip, follow-within-root or follow-all")
+	runCmd.Flags().Int64("max-file-size", 0, "skip files bigger than this many bytes (0 for no limit)")
+	runCmd.Flags().Bool("skip-binary", false, "skip files with binary content")
+	runCmd.Flags().Int("max-archive-entries", plan.DefaultInputPolicy().Archive.MaxEntries, "maximum number of entries unpacked from an archive (0 for no limit)")
+	runCmd.Flags().Int64("max-archive-size", plan.DefaultInputPolicy().Archive.MaxSize, "maximum total size in bytes unpacked from an archive (0 for no limit)")
+
+	addOutputFlags(runCmd)
+	addFilterFlags(runCmd)
+	addSummaryFlags(runCmd)
 }
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/infragov-project/infrarun/pkg/infrarun/report"
+	"github.com/olekukonko/tablewriter"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+	"github.com/spf13/cobra"
+)
+
+func writeSummaryTable(w io.Writer, summary *report.Summary) error {
+	fmt.Fprintf(w, "%d findings (%d suppressed, %d fixed since the baseline)\n", summary.Findings, summary.Suppressed, summary.Absent)
+
+	runs := tablewriter.NewWriter(w)
+	runs.Header("Run", "Findings", "Duration", "Status")
+
+	for _, r := range summary.Runs {
+		label := r.Tool
+
+		if r.AutomationID != "" {
+			label = r.AutomationID
+		}
+
+		status := "ok"
+
+		if !r.Successful {
+			status = "failed"
+		}
+
+		err := runs.Append(label, strconv.Itoa(r.Findings), fmt.Sprintf("%.1fs", r.Duration), status)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err := runs.Render()
+
+	if err != nil {
+		return err
+	}
+
+	counts := []struct {
+		Key    string
+		Counts []report.SummaryCount
+	}{
+		{"Tool", summary.ByTool},
+		{"Level", summary.ByLevel},
+		{"File", summary.TopFiles},
+	}
+
+	for _, group := range counts {
+		if len(group.Counts) == 0 {
+			continue
+		}
+
+		table := tablewriter.NewWriter(w)
+		table.Header(group.Key, "Findings")
+
+		for _, c := range group.Counts {
+			err = table.Append(c.Key, strconv.Itoa(c.Count))
+
+			if err != nil {
+				return err
+			}
+		}
+
+		err = table.Render()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(summary.ByRule) == 0 {
+		return nil
+	}
+
+	rules := tablewriter.NewWriter(w)
+	rules.Header("Tool", "Rule", "Findings")
+
+	for _, c := range summary.ByRule {
+		err = rules.Append(c.Tool, c.Rule, strconv.Itoa(c.Count))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return rules.Render()
+}
+
+func writeSummary(w io.Writer, summary *report.Summary, format string) error {
+	switch format {
+	case "table":
+		return writeSummaryTable(w, summary)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(summary)
+	default:
+		return fmt.Errorf("unknown summary format: %s", format)
+	}
+}
+
+// printSummary writes the summary of rep in the formats given by the summary flag, if any, to the file given by
+// the summary-file flag or to the standard error.
+func printSummary(cmd *cobra.Command, rep *sarif.Report) error {
+	formats, err := cmd.Flags().GetStringSlice("summary")
+
+	if err != nil || len(formats) == 0 {
+		return err
+	}
+
+	topFiles, err := cmd.Flags().GetInt("top-files")
+
+	if err != nil {
+		return err
+	}
+
+	summaryPath, err := cmd.Flags().GetString("summary-file")
+
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stderr
+
+	if summaryPath != "" {
+		file, err := os.Create(summaryPath)
+
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		w = file
+	}
+
+	summary := report.Summarize(rep, topFiles)
+
+	for _, format := range formats {
+		err = writeSummary(w, summary, format)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addSummaryFlags adds the flags of the summary of the report to cmd.
+func addSummaryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("summary", nil, "print a summary of the findings and runs: table, json or both")
+	cmd.Flags().String("summary-file", "", "file to write the summary to, instead of the standard error")
+	cmd.Flags().Int("top-files", 10, "files with the most findings listed in the summary (-1 for every file)")
+}
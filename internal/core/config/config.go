@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/infragov-project/infrarun/internal/core/results"
+	"github.com/infragov-project/infrarun/internal/core/taxonomy"
+	"github.com/infragov-project/infrarun/internal/core/tools"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the project configuration file looked up in the analyzed directory.
+const FileName = "infrarun.yaml"
+
+// Config holds the project settings that change how reports are processed.
+type Config struct {
+	Dedup    DedupConfig                  `yaml:"dedup"`
+	Severity map[string]tools.SeverityMap `yaml:"severity"` // Overrides of the severity maps of tools, by tool name
+	Taxonomy []taxonomy.Category          `yaml:"taxonomy"` // Categories extending the default taxonomy
+	Filters  FilterConfig                 `yaml:"filters"`
+}
+
+type DedupConfig struct {
+	Mode  string              `yaml:"mode"`  // off, report or collapse
+	Rules map[string][]string `yaml:"rules"` // Groups of equivalent rules, each written as tool:rule
+}
+
+// FilterConfig selects the results kept in the report. Empty settings keep every result.
+type FilterConfig struct {
+	MinLevel     string   `yaml:"min_level"`     // none, note, warning or error
+	Rules        []string `yaml:"rules"`         // Rule id globs or tool:rule
+	ExcludeRules []string `yaml:"exclude_rules"` // Rule id globs or tool:rule
+	Tools        []string `yaml:"tools"`
+	Paths        []string `yaml:"paths"`      // Globs relative to the analyzed path
+	Categories   []string `yaml:"categories"` // Taxonomy category ids, or CWE-<id>
+}
+
+func (f FilterConfig) validate() error {
+	if f.MinLevel == "" {
+		return nil
+	}
+
+	if _, err := results.ParseLevel(f.MinLevel); err != nil {
+		return fmt.Errorf("filters: %w", err)
+	}
+
+	return nil
+}
+
+func FromYaml(content []byte) (*Config, error) {
+	var c Config
+
+	if err := yaml.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+
+	for tool, severity := range c.Severity {
+		if err := severity.ValidateOverride(); err != nil {
+			return nil, fmt.Errorf("severity of %s: %w", tool, err)
+		}
+	}
+
+	if err := taxonomy.ValidateCategories(c.Taxonomy); err != nil {
+		return nil, err
+	}
+
+	if err := c.Filters.validate(); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func Load(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return FromYaml(content)
+}
+
+// Find loads the configuration file of the project at dir. A project without one gets an empty configuration.
+func Find(dir string) (*Config, error) {
+	path := filepath.Join(dir, FileName)
+
+	_, err := os.Stat(path)
+
+	// Paths that are not directories, as archives, have none either
+	if os.IsNotExist(err) || errors.Is(err, syscall.ENOTDIR) {
+		return &Config{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Load(path)
+}
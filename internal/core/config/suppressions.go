@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SuppressionsFileName is the name of the suppressions file looked up in the analyzed directory.
+const SuppressionsFileName = "infrarun-suppressions.yaml"
+
+// Suppressions is the content of a suppressions file.
+type Suppressions struct {
+	Suppressions []Suppression `yaml:"suppressions"`
+}
+
+// Suppression accepts the findings of a rule of a tool. Owner, reason and expiry are required, so every
+// accepted risk has someone responsible for it and is reviewed again.
+type Suppression struct {
+	Tool        string `yaml:"tool"`
+	Rule        string `yaml:"rule"`        // "*" for every rule of the tool
+	Path        string `yaml:"path"`        // Glob of the files, every file if empty
+	Fingerprint string `yaml:"fingerprint"` // Optional, to accept a single finding
+	Owner       string `yaml:"owner"`
+	Reason      string `yaml:"reason"`
+	Expires     string `yaml:"expires"` // Last day the suppression applies, as YYYY-MM-DD
+}
+
+// ExpiryDate returns the parsed expiry date of s.
+func (s Suppression) ExpiryDate() (time.Time, error) {
+	return time.Parse(time.DateOnly, s.Expires)
+}
+
+func (s Suppression) validate() error {
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"tool", s.Tool},
+		{"rule", s.Rule},
+		{"owner", s.Owner},
+		{"reason", s.Reason},
+		{"expires", s.Expires},
+	}
+
+	for _, field := range required {
+		if field.value == "" {
+			return fmt.Errorf("missing %s", field.name)
+		}
+	}
+
+	if _, err := s.ExpiryDate(); err != nil {
+		return fmt.Errorf("expires is not a YYYY-MM-DD date: %s", s.Expires)
+	}
+
+	return nil
+}
+
+func SuppressionsFromYaml(content []byte) (*Suppressions, error) {
+	var s Suppressions
+
+	if err := yaml.Unmarshal(content, &s); err != nil {
+		return nil, err
+	}
+
+	for i, suppression := range s.Suppressions {
+		if err := suppression.validate(); err != nil {
+			return nil, fmt.Errorf("suppression %d: %w", i+1, err)
+		}
+	}
+
+	return &s, nil
+}
+
+func LoadSuppressions(path string) (*Suppressions, error) {
+	content, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return SuppressionsFromYaml(content)
+}
+
+// FindSuppressions loads the suppressions file of the project at dir. A project without one has no suppressions.
+func FindSuppressions(dir string) (*Suppressions, error) {
+	path := filepath.Join(dir, SuppressionsFileName)
+
+	_, err := os.Stat(path)
+
+	// Paths that are not directories, as archives, have none either
+	if os.IsNotExist(err) || errors.Is(err, syscall.ENOTDIR) {
+		return &Suppressions{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadSuppressions(path)
+}
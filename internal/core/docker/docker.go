@@ -57,6 +57,33 @@ func (engine *DockerEngine) EnsureImageExists(ctx context.Context, imageName str
 	return nil
 }
 
+// ImageInfo identifies the exact image behind an image reference.
+type ImageInfo struct {
+	ID     string
+	Digest string // Repository digest, as in name@sha256:..., empty for images that were never pushed or pulled
+	Labels map[string]string
+}
+
+func (engine *DockerEngine) InspectImage(ctx context.Context, imageName string) (ImageInfo, error) {
+	inspect, err := engine.Client.ImageInspect(ctx, imageName)
+
+	if err != nil {
+		return ImageInfo{}, err
+	}
+
+	info := ImageInfo{ID: inspect.ID}
+
+	if len(inspect.RepoDigests) > 0 {
+		info.Digest = inspect.RepoDigests[0]
+	}
+
+	if inspect.Config != nil {
+		info.Labels = inspect.Config.Labels
+	}
+
+	return info, nil
+}
+
 type ContainerInfo struct {
 	Image       string
 	Cmd         []string
@@ -64,22 +91,37 @@ type ContainerInfo struct {
 }
 
 type VolumeBind struct {
-	Host  string
-	Guest string
+	Host     string
+	Guest    string
+	ReadOnly bool
+}
+
+func (b VolumeBind) String() string {
+	if b.ReadOnly {
+		return b.Host + ":" + b.Guest + ":ro"
+	}
+
+	return b.Host + ":" + b.Guest
+}
+
+// ContainerRun is a container that ran to completion.
+type ContainerRun struct {
+	ID       string
+	ExitCode int
 }
 
-func (engine *DockerEngine) RunContainer(ctx context.Context, info ContainerInfo) (string, error) {
+func (engine *DockerEngine) RunContainer(ctx context.Context, info ContainerInfo) (*ContainerRun, error) {
 
 	resp, err := engine.Client.ContainerCreate(ctx, &container.Config{
 		Image: info.Image,
 		Cmd:   info.Cmd,
 		Tty:   false,
 	}, &container.HostConfig{
-		Binds: utils.Map(info.VolumeBinds, func(x VolumeBind) string { return x.Host + ":" + x.Guest }),
+		Binds: utils.Map(info.VolumeBinds, VolumeBind.String),
 	}, nil, nil, "")
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	containerID := resp.ID
@@ -87,16 +129,16 @@ func (engine *DockerEngine) RunContainer(ctx context.Context, info ContainerInfo
 	err = engine.Client.ContainerStart(ctx, containerID, container.StartOptions{})
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	statusCh, errCh := engine.Client.ContainerWait(ctx, containerID, container.WaitConditionNotRunning)
 
 	select {
 	case error := <-errCh: // Got error from ContainerWait
-		return "", error
-	case <-statusCh:
-		return containerID, nil
+		return nil, error
+	case status := <-statusCh:
+		return &ContainerRun{ID: containerID, ExitCode: int(status.StatusCode)}, nil
 	}
 
 }
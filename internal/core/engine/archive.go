@@ -0,0 +1,419 @@
+package engine
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ArchiveLimits bound the amount of content unpacked from an archive, to protect against archive bombs.
+// Zero values mean no limit.
+type ArchiveLimits struct {
+	MaxEntries int
+	MaxSize    int64 // Total size, in bytes, of the unpacked files
+}
+
+func DefaultArchiveLimits() ArchiveLimits {
+	return ArchiveLimits{
+		MaxEntries: 100_000,
+		MaxSize:    1 << 30,
+	}
+}
+
+var archiveExtensions = []string{".tar", ".tar.gz", ".tgz", ".zip"}
+
+// isArchive reports whether path is a regular file with the extension of one of the supported archive formats.
+func isArchive(path string) bool {
+	lower := strings.ToLower(path)
+
+	supported := slices.ContainsFunc(archiveExtensions, func(ext string) bool {
+		return strings.HasSuffix(lower, ext)
+	})
+
+	if !supported {
+		return false
+	}
+
+	info, err := os.Stat(path)
+
+	return err == nil && info.Mode().IsRegular()
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// archiveEntryPath validates the name of an archive entry and returns the path it should be extracted to.
+// Absolute names and names escaping the destination are rejected.
+func archiveEntryPath(dst string, name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+
+	if path.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("illegal path in archive: %s", name)
+	}
+
+	cleaned := path.Clean(name)
+
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("illegal path in archive: %s", name)
+	}
+
+	return filepath.Join(dst, filepath.FromSlash(cleaned)), nil
+}
+
+type archiveExtractor struct {
+	dst     string
+	limits  ArchiveLimits
+	entries int
+	size    int64
+	skipped []SkippedFile
+}
+
+func newArchiveExtractor(dst string, limits ArchiveLimits) *archiveExtractor {
+	// Links are checked against the real path of the destination
+	if real, err := filepath.EvalSymlinks(dst); err == nil {
+		dst = real
+	}
+
+	return &archiveExtractor{
+		dst:    dst,
+		limits: limits,
+	}
+}
+
+func (e *archiveExtractor) skip(name string, reason string) {
+	e.skipped = append(e.skipped, SkippedFile{Path: name, Reason: reason})
+}
+
+// entry validates the next entry of the archive and returns the path it should be extracted to.
+func (e *archiveExtractor) entry(name string) (string, error) {
+	e.entries++
+
+	if e.limits.MaxEntries > 0 && e.entries > e.limits.MaxEntries {
+		return "", fmt.Errorf("archive has more than %d entries", e.limits.MaxEntries)
+	}
+
+	return archiveEntryPath(e.dst, name)
+}
+
+// mkdirs creates the directories of dir under the destination that do not exist yet. It reports false, without
+// creating anything, if one of them is a symbolic link, since entries written through links extracted before could
+// end up anywhere.
+func (e *archiveExtractor) mkdirs(dir string) (bool, error) {
+	rel, err := filepath.Rel(e.dst, dir)
+
+	if err != nil {
+		return false, err
+	}
+
+	current := e.dst
+
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if part == "." {
+			continue
+		}
+
+		current = filepath.Join(current, part)
+
+		info, err := os.Lstat(current)
+
+		if os.IsNotExist(err) {
+			if err := os.Mkdir(current, 0755); err != nil {
+				return false, err
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return false, nil
+		}
+
+		if !info.IsDir() {
+			return false, fmt.Errorf("%s: not a directory", current)
+		}
+	}
+
+	return true, nil
+}
+
+// prepare creates the parent directories of target and reports whether the entry can be written at target,
+// that is, if neither target nor any of its parents is a symbolic link. Entries that cannot are reported as
+// skipped.
+func (e *archiveExtractor) prepare(name string, target string) (bool, error) {
+	ok, err := e.mkdirs(filepath.Dir(target))
+
+	if err == nil && ok {
+		info, lerr := os.Lstat(target)
+		ok = lerr != nil || info.Mode()&os.ModeSymlink == 0
+	}
+
+	if err == nil && !ok {
+		e.skip(name, "path through a symbolic link")
+	}
+
+	return ok, err
+}
+
+func (e *archiveExtractor) dir(name string, target string) error {
+	ok, err := e.mkdirs(target)
+
+	if err == nil && !ok {
+		e.skip(name, "path through a symbolic link")
+	}
+
+	return err
+}
+
+func (e *archiveExtractor) file(name string, target string, content io.Reader) error {
+	ok, err := e.prepare(name, target)
+
+	if err != nil || !ok {
+		return err
+	}
+
+	output, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	defer output.Close()
+
+	if e.limits.MaxSize <= 0 {
+		_, err = io.Copy(output, content)
+		return err
+	}
+
+	remaining := e.limits.MaxSize - e.size
+	written, err := io.Copy(output, io.LimitReader(content, remaining+1))
+	e.size += written
+
+	if err != nil {
+		return err
+	}
+
+	if written > remaining {
+		return fmt.Errorf("archive content is larger than %d bytes", e.limits.MaxSize)
+	}
+
+	return nil
+}
+
+// symlink creates a symbolic link at target pointing to link, as long as the link resolves inside of the
+// destination, both lexically and through the links already extracted. Links that do not are reported as skipped.
+func (e *archiveExtractor) symlink(name string, target string, link string) error {
+	if filepath.IsAbs(link) || path.IsAbs(link) {
+		e.skip(name, "symbolic link with an absolute target")
+		return nil
+	}
+
+	resolved := filepath.Join(filepath.Dir(target), filepath.FromSlash(link))
+
+	if !isWithin(e.dst, resolved) {
+		e.skip(name, "symbolic link points outside of the input root")
+		return nil
+	}
+
+	// Not cleaned lexically, so that .. applies to where the links before it point to
+	unclean := filepath.Dir(target) + string(filepath.Separator) + filepath.FromSlash(link)
+
+	if real, err := filepath.EvalSymlinks(unclean); err == nil && !isWithin(e.dst, real) {
+		e.skip(name, "symbolic link points outside of the input root")
+		return nil
+	}
+
+	ok, err := e.prepare(name, target)
+
+	if err != nil || !ok {
+		return err
+	}
+
+	return os.Symlink(link, target)
+}
+
+// extractTar unpacks the tar stream r into dst. Only directories, regular files and symbolic links that stay
+// inside dst are extracted, and nothing is written through a symbolic link, every other entry is reported as
+// skipped.
+func extractTar(r io.Reader, dst string, limits ArchiveLimits) ([]SkippedFile, error) {
+	extractor := newArchiveExtractor(dst, limits)
+
+	reader := tar.NewReader(r)
+
+	for {
+		header, err := reader.Next()
+
+		if err == io.EOF {
+			return extractor.skipped, nil
+		}
+
+		if err != nil {
+			return extractor.skipped, err
+		}
+
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue // Metadata only, as the commit id written by git archive
+		}
+
+		target, err := extractor.entry(header.Name)
+
+		if err != nil {
+			return extractor.skipped, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = extractor.dir(header.Name, target)
+		case tar.TypeReg:
+			err = extractor.file(header.Name, target, reader)
+		case tar.TypeSymlink:
+			err = extractor.symlink(header.Name, target, header.Linkname)
+		default:
+			extractor.skip(header.Name, "unsupported archive entry type")
+		}
+
+		if err != nil {
+			return extractor.skipped, err
+		}
+	}
+}
+
+// extractZip unpacks the zip file at path into dst, following the same rules as [extractTar].
+func extractZip(path string, dst string, limits ArchiveLimits) ([]SkippedFile, error) {
+	reader, err := zip.OpenReader(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	extractor := newArchiveExtractor(dst, limits)
+
+	for _, f := range reader.File {
+		target, err := extractor.entry(f.Name)
+
+		if err != nil {
+			return extractor.skipped, err
+		}
+
+		mode := f.Mode()
+
+		switch {
+		case mode.IsDir():
+			err = extractor.dir(f.Name, target)
+		case mode.IsRegular():
+			err = extractZipFile(extractor, f, target)
+		case mode&os.ModeSymlink != 0:
+			err = extractZipSymlink(extractor, f, target)
+		default:
+			extractor.skip(f.Name, "unsupported archive entry type")
+		}
+
+		if err != nil {
+			return extractor.skipped, err
+		}
+	}
+
+	return extractor.skipped, nil
+}
+
+func extractZipFile(extractor *archiveExtractor, f *zip.File, target string) error {
+	content, err := f.Open()
+
+	if err != nil {
+		return err
+	}
+
+	defer content.Close()
+
+	return extractor.file(f.Name, target, content)
+}
+
+func extractZipSymlink(extractor *archiveExtractor, f *zip.File, target string) error {
+	content, err := f.Open()
+
+	if err != nil {
+		return err
+	}
+
+	defer content.Close()
+
+	// The link target is stored as the content of the entry
+	link, err := io.ReadAll(io.LimitReader(content, 4096))
+
+	if err != nil {
+		return err
+	}
+
+	return extractor.symlink(f.Name, target, string(link))
+}
+
+// extractArchive unpacks the archive at path into a new temporary directory.
+func extractArchive(path string, limits ArchiveLimits) (string, []SkippedFile, error) {
+	tmpDir, err := os.MkdirTemp("", "infrarun-archive-")
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	lower := strings.ToLower(path)
+
+	if strings.HasSuffix(lower, ".zip") {
+		skipped, err := extractZip(path, tmpDir, limits)
+		return tmpDir, skipped, err
+	}
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		return tmpDir, nil, err
+	}
+
+	defer file.Close()
+
+	var stream io.Reader = file
+
+	if strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(file)
+
+		if err != nil {
+			return tmpDir, nil, err
+		}
+
+		defer gz.Close()
+
+		stream = gz
+	}
+
+	skipped, err := extractTar(stream, tmpDir, limits)
+
+	return tmpDir, skipped, err
+}
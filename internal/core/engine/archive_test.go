@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtractTar(t *testing.T) {
+
+	type entry struct {
+		Name     string
+		Type     byte
+		Content  string
+		Linkname string
+	}
+
+	type Test struct {
+		Name        string
+		Entries     []entry
+		Limits      ArchiveLimits
+		WantSkipped []SkippedFile
+		WantErr     bool
+	}
+
+	tests := []Test{
+		{
+			Name: "regular files",
+			Entries: []entry{
+				{Name: "dir/", Type: tar.TypeDir},
+				{Name: "dir/main.tf", Type: tar.TypeReg, Content: "resource {}"},
+				{Name: "link.tf", Type: tar.TypeSymlink, Linkname: "dir/main.tf"},
+			},
+		},
+		{
+			Name: "path traversal",
+			Entries: []entry{
+				{Name: "../evil.tf", Type: tar.TypeReg, Content: "evil"},
+			},
+			WantErr: true,
+		},
+		{
+			Name: "absolute path",
+			Entries: []entry{
+				{Name: "/etc/evil.tf", Type: tar.TypeReg, Content: "evil"},
+			},
+			WantErr: true,
+		},
+		{
+			Name: "escaping symlinks",
+			Entries: []entry{
+				{Name: "up", Type: tar.TypeSymlink, Linkname: "../../etc"},
+				{Name: "abs", Type: tar.TypeSymlink, Linkname: "/etc/passwd"},
+				{Name: "fifo", Type: tar.TypeFifo},
+			},
+			WantSkipped: []SkippedFile{
+				{Path: "up", Reason: "symbolic link points outside of the input root"},
+				{Path: "abs", Reason: "symbolic link with an absolute target"},
+				{Path: "fifo", Reason: "unsupported archive entry type"},
+			},
+		},
+		{
+			Name: "write through chained symlinks",
+			Entries: []entry{
+				{Name: "x/", Type: tar.TypeDir},
+				{Name: "x/c", Type: tar.TypeSymlink, Linkname: ".."},
+				{Name: "x/c/d", Type: tar.TypeSymlink, Linkname: "../.."},
+				{Name: "d/pwned.txt", Type: tar.TypeReg, Content: "pwned"},
+				{Name: "x/c/e.txt", Type: tar.TypeReg, Content: "pwned"},
+			},
+			WantSkipped: []SkippedFile{
+				{Path: "x/c/d", Reason: "symbolic link points outside of the input root"},
+				{Path: "x/c/e.txt", Reason: "path through a symbolic link"},
+			},
+		},
+		{
+			Name: "symlink through symlinks",
+			Entries: []entry{
+				{Name: "x/", Type: tar.TypeDir},
+				{Name: "x/c", Type: tar.TypeSymlink, Linkname: ".."},
+				{Name: "up", Type: tar.TypeSymlink, Linkname: "x/c/.."},
+				{Name: "up/pwned.txt", Type: tar.TypeReg, Content: "pwned"},
+			},
+			WantSkipped: []SkippedFile{
+				{Path: "up", Reason: "symbolic link points outside of the input root"},
+			},
+		},
+		{
+			Name: "too many entries",
+			Entries: []entry{
+				{Name: "a.tf", Type: tar.TypeReg, Content: "a"},
+				{Name: "b.tf", Type: tar.TypeReg, Content: "b"},
+			},
+			Limits:  ArchiveLimits{MaxEntries: 1},
+			WantErr: true,
+		},
+		{
+			Name: "too big",
+			Entries: []entry{
+				{Name: "a.tf", Type: tar.TypeReg, Content: "0123456789"},
+				{Name: "b.tf", Type: tar.TypeReg, Content: "0123456789"},
+			},
+			Limits:  ArchiveLimits{MaxSize: 15},
+			WantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			writer := tar.NewWriter(&buf)
+
+			for _, e := range tt.Entries {
+				header := &tar.Header{
+					Name:     e.Name,
+					Typeflag: e.Type,
+					Linkname: e.Linkname,
+					Size:     int64(len(e.Content)),
+					Mode:     0644,
+				}
+
+				if err := writer.WriteHeader(header); err != nil {
+					t.Fatal(err)
+				}
+
+				if _, err := writer.Write([]byte(e.Content)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			base := t.TempDir()
+			dst := filepath.Join(base, "a", "b")
+
+			if err := os.MkdirAll(dst, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			skipped, err := extractTar(&buf, dst, tt.Limits)
+
+			if tt.WantErr != (err != nil) {
+				t.Errorf("got error %v, want error %v", err, tt.WantErr)
+			} else if !reflect.DeepEqual(tt.WantSkipped, skipped) {
+				t.Errorf("got %#v, want %#v", skipped, tt.WantSkipped)
+			}
+
+			// Nothing is written outside of the destination
+			err = filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() && !isWithin(dst, path) {
+					t.Errorf("got %s written outside of the destination", path)
+				}
+
+				return err
+			})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+
+	}
+
+}
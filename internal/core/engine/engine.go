@@ -2,24 +2,38 @@ package engine
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"time"
 
-	"github.com/bmatcuk/doublestar"
 	"github.com/infragov-project/infrarun/internal/core/docker"
+	"github.com/infragov-project/infrarun/internal/core/results"
 	"github.com/infragov-project/infrarun/internal/core/tools"
 	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
 )
 
 type ToolExecution struct {
-	Path   string
-	Glob   string
-	Tool   *tools.ToolInstance
-	Report *sarif.Report
-	Err    error
+	Path        string
+	Ref         string // Git revision to take the input from, instead of the working copy
+	Glob        string
+	Excludes    []string
+	ProjectRoot string // Project of a split monorepo analyzed by this execution, relative to Path
+	Input       InputPolicy
+	Tool        *tools.ToolInstance
+	Report      *sarif.Report
+	Skipped     []SkippedFile      // Files left out of the staged input, filled in by Execute
+	Source      SourceInfo         // Origin of the staged input, filled in by Execute
+	InputDir    string             // Staged input, filled in by Execute and valid until the input is released
+	Invocation  results.Invocation // How the tool was run, filled in by Execute
+	Err         error
+
+	automationID string // Pinned by Execute, see AutomationID
 }
 
 func NewToolExecution(tool *tools.ToolInstance, path string, glob string) (*ToolExecution, error) {
@@ -30,14 +44,53 @@ func NewToolExecution(tool *tools.ToolInstance, path string, glob string) (*Tool
 	}
 
 	return &ToolExecution{
-		Tool: tool,
-		Glob: glob,
-		Path: absPath,
+		Tool:  tool,
+		Glob:  glob,
+		Path:  absPath,
+		Input: DefaultInputPolicy(),
 	}, nil
 }
 
+// OptionsHash returns a short hash identifying the options of tool.
+func OptionsHash(options map[string]any) string {
+	content, err := json.Marshal(options) // Map keys are sorted, so equal options give equal hashes
+
+	if err != nil {
+		content = fmt.Appendf(nil, "%v", options)
+	}
+
+	hash := sha256.Sum256(content)
+
+	return hex.EncodeToString(hash[:6])
+}
+
+// AutomationID returns the identifier of toolExecution, made of the name of the tool, the hash of its options, the
+// analyzed path and the git ref it was taken from, if any. The path is relative to the top level of the git work
+// tree containing it, so that it is the same across machines and working directories, or is the base name of the
+// analyzed path outside of work trees. Once toolExecution is executed, the id is the one it was executed with.
+func (toolExecution *ToolExecution) AutomationID() string {
+	if toolExecution.automationID != "" {
+		return toolExecution.automationID
+	}
+
+	base := filepath.Base(toolExecution.Path)
+
+	if prefix, err := git(toolExecution.Path, "rev-parse", "--show-prefix"); err == nil {
+		base = prefix
+	}
+
+	id := toolExecution.Tool.Name + "/" + OptionsHash(toolExecution.Tool.Options) + "/" + path.Join(".", filepath.ToSlash(base), toolExecution.ProjectRoot)
+
+	if toolExecution.Ref != "" {
+		id += "@" + toolExecution.Ref
+	}
+
+	return id
+}
+
 type InfrarunEngine struct {
 	Backend *docker.DockerEngine
+	Inputs  *InputStager
 }
 
 func NewInfrarunEngine() (*InfrarunEngine, error) {
@@ -49,24 +102,45 @@ func NewInfrarunEngine() (*InfrarunEngine, error) {
 
 	return &InfrarunEngine{
 		Backend: backend,
+		Inputs:  NewInputStager(),
 	}, nil
 }
 
+// Execute runs the tool of toolExecution and returns its raw output. The input of toolExecution must have been
+// acquired from engine.Inputs beforehand, and is left for the caller to release.
 func (engine *InfrarunEngine) Execute(ctx context.Context, toolExecution *ToolExecution) ([]byte, error) {
+	toolExecution.automationID = toolExecution.AutomationID()
+
+	toolExecution.Invocation = results.Invocation{
+		Image: toolExecution.Tool.Image,
+		Cmd:   toolExecution.Tool.Cmd,
+	}
+
 	if err := engine.Backend.EnsureImageExists(ctx, toolExecution.Tool.Image); err != nil {
 		return nil, err
 	}
 
-	inputTmpPath, err := prepareInputDir(toolExecution.Path, toolExecution.Glob)
+	inputDir, err := engine.Inputs.Stage(toolExecution)
 
-	defer os.Remove(inputTmpPath)
+	if err != nil {
+		return nil, err
+	}
+
+	toolExecution.InputDir = inputDir
+
+	image, err := engine.Backend.InspectImage(ctx, toolExecution.Tool.Image)
 
 	if err != nil {
 		return nil, err
 	}
 
+	toolExecution.Invocation.ImageID = image.ID
+	toolExecution.Invocation.ImageDigest = image.Digest
+	toolExecution.Invocation.ImageLabels = image.Labels
+
+	// Runs of a single project of the input only see its directory
 	volumeBinds := []docker.VolumeBind{
-		{Host: inputTmpPath, Guest: toolExecution.Tool.InputPath},
+		{Host: filepath.Join(inputDir, filepath.FromSlash(toolExecution.ProjectRoot)), Guest: toolExecution.Tool.InputPath, ReadOnly: true},
 	}
 
 	outputDir := ""
@@ -88,18 +162,24 @@ func (engine *InfrarunEngine) Execute(ctx context.Context, toolExecution *ToolEx
 		})
 	}
 
-	containerID, err := engine.Backend.RunContainer(ctx, docker.ContainerInfo{
+	toolExecution.Invocation.Start = time.Now().UTC()
+
+	container, err := engine.Backend.RunContainer(ctx, docker.ContainerInfo{
 		Image:       toolExecution.Tool.Image,
 		Cmd:         toolExecution.Tool.Cmd,
 		VolumeBinds: volumeBinds,
 	})
 
+	toolExecution.Invocation.End = time.Now().UTC()
+
 	if err != nil {
 		return nil, err
 	}
 
+	toolExecution.Invocation.ExitCode = container.ExitCode
+
 	if toolExecution.Tool.CaptureStdout {
-		data, err := engine.Backend.CaptureStdOut(ctx, containerID)
+		data, err := engine.Backend.CaptureStdOut(ctx, container.ID)
 
 		if err != nil {
 			return nil, err
@@ -142,76 +222,3 @@ func extractStdoutFrames(data []byte) ([]byte, error) {
 
 	return out, nil
 }
-
-func copyFile(src, dst string) error {
-	input, err := os.Open(src)
-
-	if err != nil {
-		return err
-	}
-
-	defer input.Close()
-
-	// Create intermediate directories if needed
-	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
-		return err
-	}
-
-	output, err := os.Create(dst)
-
-	if err != nil {
-		return err
-	}
-
-	_, err = io.Copy(output, input)
-
-	return err
-}
-
-func prepareInputDir(basePath string, pattern string) (string, error) {
-	absBase, err := filepath.Abs(basePath)
-
-	if err != nil {
-		return "", err
-	}
-
-	fullPattern := filepath.Join(absBase, pattern)
-
-	files, err := doublestar.Glob(fullPattern)
-
-	if err != nil {
-		return "", err
-	}
-
-	tmpDir, err := os.MkdirTemp("", "infrarun-input-")
-
-	if err != nil {
-		return "", err
-	}
-
-	for _, file := range files {
-		info, err := os.Stat(file)
-
-		if err != nil {
-			return "", err
-		}
-
-		if info.IsDir() {
-			continue
-		}
-
-		relPath, err := filepath.Rel(absBase, file)
-
-		if err != nil {
-			return "", err
-		}
-
-		dst := filepath.Join(tmpDir, relPath)
-
-		if err := copyFile(file, dst); err != nil {
-			return "", err
-		}
-	}
-
-	return tmpDir, nil
-}
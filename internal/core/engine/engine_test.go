@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/infragov-project/infrarun/internal/core/tools"
+)
+
+func TestAutomationID(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available: ", err)
+	}
+
+	repo := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "project")
+
+	if _, err := git(repo, "init", "-q"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{filepath.Join(repo, "infra"), outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	terraform := OptionsHash(map[string]any{"tech": "terraform", "flags": []any{"-v"}})
+	ansible := OptionsHash(map[string]any{"tech": "ansible", "flags": []any{"-v"}})
+
+	type Test struct {
+		Name        string
+		Options     map[string]any
+		Path        string
+		Ref         string
+		ProjectRoot string
+		Want        string
+	}
+
+	tests := []Test{
+		{Name: "top level", Options: map[string]any{"flags": []any{"-v"}, "tech": "terraform"}, Path: repo, Want: "GLITCH/" + terraform + "/."},
+		{Name: "other options", Options: map[string]any{"tech": "ansible", "flags": []any{"-v"}}, Path: repo, Want: "GLITCH/" + ansible + "/."},
+		{Name: "subdirectory", Options: map[string]any{"tech": "terraform", "flags": []any{"-v"}}, Path: filepath.Join(repo, "infra"), Want: "GLITCH/" + terraform + "/infra"},
+		{Name: "project root", Options: map[string]any{"tech": "terraform", "flags": []any{"-v"}}, Path: repo, ProjectRoot: "envs/prod", Want: "GLITCH/" + terraform + "/envs/prod"},
+		{Name: "ref", Options: map[string]any{"tech": "terraform", "flags": []any{"-v"}}, Path: filepath.Join(repo, "infra"), Ref: "v1.0", Want: "GLITCH/" + terraform + "/infra@v1.0"},
+		{Name: "outside of a work tree", Options: map[string]any{"tech": "terraform", "flags": []any{"-v"}}, Path: outside, Want: "GLITCH/" + terraform + "/project"},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			exec := &ToolExecution{
+				Path:        tt.Path,
+				Ref:         tt.Ref,
+				ProjectRoot: tt.ProjectRoot,
+				Tool:        &tools.ToolInstance{Name: "GLITCH", Options: tt.Options},
+			}
+
+			if got := exec.AutomationID(); got != tt.Want {
+				t.Errorf("got %#v, want %#v", got, tt.Want)
+			}
+		})
+
+	}
+
+	if terraform == ansible {
+		t.Errorf("got the same hash for different options: %s", terraform)
+	}
+
+}
@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// sanitizeRemote returns the URI of a git remote without the credentials it may embed, as the tokens written in the
+// remotes of CI checkouts. Remotes in the scp-like syntax, as git@host:path, are turned into ssh URIs.
+func sanitizeRemote(remote string) string {
+	if !strings.Contains(remote, "://") {
+		host, p, ok := strings.Cut(remote, ":")
+
+		// Local paths, as /srv/repo.git or C:/repo, are left as they are
+		if !ok || strings.Contains(host, "/") || len(host) == 1 {
+			return remote
+		}
+
+		if _, after, found := strings.Cut(host, "@"); found {
+			host = after
+		}
+
+		return "ssh://" + host + "/" + strings.TrimPrefix(p, "/")
+	}
+
+	u, err := url.Parse(remote)
+
+	if err != nil {
+		// Drop the user information by hand, as for passwords with characters that should have been escaped
+		scheme, rest, _ := strings.Cut(remote, "://")
+		authority, p, _ := strings.Cut(rest, "/")
+
+		if i := strings.LastIndex(authority, "@"); i >= 0 {
+			authority = authority[i+1:]
+		}
+
+		return scheme + "://" + authority + "/" + p
+	}
+
+	u.User = nil
+
+	return u.String()
+}
+
+// gitRepositoryURI returns the URI of the origin remote of the repository containing dir, without credentials, or
+// the location of the repository itself if it has no such remote.
+func gitRepositoryURI(dir string) (string, error) {
+	if remote, err := git(dir, "remote", "get-url", "origin"); err == nil && remote != "" {
+		return sanitizeRemote(remote), nil
+	}
+
+	toplevel, err := git(dir, "rev-parse", "--show-toplevel")
+
+	if err != nil {
+		return "", err
+	}
+
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(toplevel)}
+
+	return u.String(), nil
+}
+
+// GitPrefix returns the path of dir relative to the top level of the git work tree containing it, with a trailing
+// slash, or an empty string if dir is the top level or is not in a work tree.
+func GitPrefix(dir string) string {
+	prefix, err := git(dir, "rev-parse", "--show-prefix")
+
+	if err != nil {
+		return ""
+	}
+
+	return prefix
+}
+
+// exportGitTree writes the tree of ref, restricted to the directory dir inside of the repository, into
+// a new temporary directory. The working copy is left untouched.
+func exportGitTree(dir string, ref string) (string, SourceInfo, []SkippedFile, error) {
+	var source SourceInfo
+
+	toplevel, err := git(dir, "rev-parse", "--show-toplevel")
+
+	if err != nil {
+		return "", source, nil, err
+	}
+
+	prefix, err := git(dir, "rev-parse", "--show-prefix")
+
+	if err != nil {
+		return "", source, nil, err
+	}
+
+	commit, err := git(dir, "rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+
+	if err != nil {
+		return "", source, nil, err
+	}
+
+	repository, err := gitRepositoryURI(dir)
+
+	if err != nil {
+		return "", source, nil, err
+	}
+
+	source = SourceInfo{
+		Ref:           ref,
+		Revision:      commit,
+		RepositoryURI: repository,
+	}
+
+	tmpDir, err := os.MkdirTemp("", "infrarun-git-")
+
+	if err != nil {
+		return "", source, nil, err
+	}
+
+	// Archives are taken from the top level, since git archive refuses to run from untracked directories
+	cmd := exec.Command("git", "-C", toplevel, "archive", "--format=tar", commit+":"+prefix)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+
+	if err != nil {
+		return tmpDir, source, nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return tmpDir, source, nil, err
+	}
+
+	skipped, extractErr := extractTar(stdout, tmpDir, ArchiveLimits{})
+
+	// Drain whatever is left so git is not blocked on a full pipe
+	_, _ = io.Copy(io.Discard, stdout)
+
+	if err := cmd.Wait(); err != nil {
+		return tmpDir, source, skipped, fmt.Errorf("git archive: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return tmpDir, source, skipped, extractErr
+}
@@ -0,0 +1,553 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/infragov-project/infrarun/internal/core/utils"
+)
+
+// SymlinkPolicy controls what happens to symbolic links found while staging the input of a tool.
+type SymlinkPolicy int
+
+const (
+	// SkipSymlinks leaves every symbolic link out of the staged input.
+	SkipSymlinks SymlinkPolicy = iota
+	// FollowSymlinksWithinRoot follows symbolic links whose target is inside the input root.
+	FollowSymlinksWithinRoot
+	// FollowAllSymlinks follows every symbolic link, wherever it points to.
+	FollowAllSymlinks
+)
+
+var symlinkPolicyNames = map[SymlinkPolicy]string{
+	SkipSymlinks:             "skip",
+	FollowSymlinksWithinRoot: "follow-within-root",
+	FollowAllSymlinks:        "follow-all",
+}
+
+func (p SymlinkPolicy) String() string {
+	name, ok := symlinkPolicyNames[p]
+
+	if !ok {
+		return fmt.Sprintf("SymlinkPolicy(%d)", int(p))
+	}
+
+	return name
+}
+
+func ParseSymlinkPolicy(name string) (SymlinkPolicy, error) {
+	for policy, n := range symlinkPolicyNames {
+		if n == name {
+			return policy, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown symlink policy: %s", name)
+}
+
+// InputPolicy describes which files are copied into the input directory of a tool.
+type InputPolicy struct {
+	Symlinks    SymlinkPolicy
+	MaxFileSize int64 // Files bigger than this (in bytes) are skipped. Zero means no limit.
+	SkipBinary  bool
+	Archive     ArchiveLimits // Limits applied when the input path is an archive
+}
+
+func DefaultInputPolicy() InputPolicy {
+	return InputPolicy{
+		Symlinks: FollowSymlinksWithinRoot,
+		Archive:  DefaultArchiveLimits(),
+	}
+}
+
+// SkippedFile is a file that matched the input pattern but was not staged, together with the reason why.
+type SkippedFile struct {
+	Path   string
+	Reason string
+}
+
+// Amount of bytes inspected when looking for binary content, the same heuristic used by git.
+const binarySniffLength = 8000
+
+type inputFile struct {
+	Source string // Path to read the content from, after resolving symlinks
+	Rel    string // Path relative to the input root
+}
+
+type inputCollector struct {
+	root     string
+	pattern  string
+	excludes []string
+	policy   InputPolicy
+	visited  map[string]bool
+	files    []inputFile
+	skipped  []SkippedFile
+}
+
+func (c *inputCollector) skip(rel string, reason string) {
+	c.skipped = append(c.skipped, SkippedFile{Path: filepath.ToSlash(rel), Reason: reason})
+}
+
+func (c *inputCollector) walk(dir string, relDir string) error {
+	real, err := filepath.EvalSymlinks(dir)
+
+	if err != nil {
+		return err
+	}
+
+	if c.visited[real] {
+		c.skip(relDir, "symbolic link cycle")
+		return nil
+	}
+
+	c.visited[real] = true
+	defer delete(c.visited, real)
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		full := filepath.Join(dir, entry.Name())
+		rel := filepath.Join(relDir, entry.Name())
+
+		info, err := entry.Info()
+
+		if err != nil {
+			return err
+		}
+
+		if c.excluded(rel) {
+			continue
+		}
+
+		source := full
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			var reason string
+			source, info, reason = c.resolveSymlink(full)
+
+			if reason != "" {
+				if c.matches(rel) {
+					c.skip(rel, reason)
+				}
+				continue
+			}
+		}
+
+		if info.IsDir() {
+			if err := c.walk(source, rel); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !c.matches(rel) {
+			continue
+		}
+
+		if !info.Mode().IsRegular() {
+			c.skip(rel, "not a regular file ("+fileKind(info.Mode())+")")
+			continue
+		}
+
+		if c.policy.MaxFileSize > 0 && info.Size() > c.policy.MaxFileSize {
+			c.skip(rel, fmt.Sprintf("file is larger than %d bytes", c.policy.MaxFileSize))
+			continue
+		}
+
+		if c.policy.SkipBinary {
+			binary, err := isBinaryFile(source)
+
+			if err != nil {
+				return err
+			}
+
+			if binary {
+				c.skip(rel, "binary file")
+				continue
+			}
+		}
+
+		c.files = append(c.files, inputFile{Source: source, Rel: rel})
+	}
+
+	return nil
+}
+
+// resolveSymlink applies the symlink policy to the link at path. It returns the resolved target and its
+// information, or a non-empty reason in case the link must be skipped.
+func (c *inputCollector) resolveSymlink(path string) (string, os.FileInfo, string) {
+	if c.policy.Symlinks == SkipSymlinks {
+		return "", nil, "symbolic link"
+	}
+
+	target, err := filepath.EvalSymlinks(path)
+
+	if err != nil {
+		return "", nil, "broken symbolic link"
+	}
+
+	if c.policy.Symlinks == FollowSymlinksWithinRoot && !isWithin(c.root, target) {
+		return "", nil, "symbolic link points outside of the input root"
+	}
+
+	info, err := os.Stat(target)
+
+	if err != nil {
+		return "", nil, "broken symbolic link"
+	}
+
+	return target, info, ""
+}
+
+func (c *inputCollector) matches(rel string) bool {
+	matched, err := doublestar.PathMatch(c.pattern, rel)
+
+	return err == nil && matched
+}
+
+func (c *inputCollector) excluded(rel string) bool {
+	for _, exclude := range c.excludes {
+		if matched, err := doublestar.PathMatch(exclude, rel); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isWithin(root string, path string) bool {
+	rel, err := filepath.Rel(root, path)
+
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func fileKind(mode os.FileMode) string {
+	switch {
+	case mode&os.ModeNamedPipe != 0:
+		return "named pipe"
+	case mode&os.ModeSocket != 0:
+		return "socket"
+	case mode&os.ModeDevice != 0:
+		return "device"
+	default:
+		return "irregular file"
+	}
+}
+
+func isBinaryFile(path string) (bool, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer file.Close()
+
+	buf := make([]byte, binarySniffLength)
+	n, err := io.ReadFull(file, buf)
+
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+
+	return bytes.IndexByte(buf[:n], 0) != -1, nil
+}
+
+// collectInputFiles walks basePath and returns every file matching pattern (and none of excludes) that is
+// allowed by policy, together with the files that matched but were left out.
+func collectInputFiles(basePath string, pattern string, excludes []string, policy InputPolicy) ([]inputFile, []SkippedFile, error) {
+	absBase, err := filepath.Abs(basePath)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root, err := filepath.EvalSymlinks(absBase)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	collector := &inputCollector{
+		root:     root,
+		pattern:  filepath.Clean(pattern),
+		excludes: utils.Map(excludes, filepath.Clean),
+		policy:   policy,
+		visited:  make(map[string]bool),
+	}
+
+	if err := collector.walk(root, ""); err != nil {
+		return nil, nil, err
+	}
+
+	return collector.files, collector.skipped, nil
+}
+
+func copyFile(src, dst string) error {
+	input, err := os.Open(src)
+
+	if err != nil {
+		return err
+	}
+
+	defer input.Close()
+
+	// Create intermediate directories if needed
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	output, err := os.Create(dst)
+
+	if err != nil {
+		return err
+	}
+
+	defer output.Close()
+
+	_, err = io.Copy(output, input)
+
+	return err
+}
+
+func prepareInputDir(basePath string, pattern string, excludes []string, policy InputPolicy) (string, []SkippedFile, error) {
+	files, skipped, err := collectInputFiles(basePath, pattern, excludes, policy)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "infrarun-input-")
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, file := range files {
+		dst := filepath.Join(tmpDir, file.Rel)
+
+		if err := copyFile(file.Source, dst); err != nil {
+			return tmpDir, nil, err
+		}
+	}
+
+	return tmpDir, skipped, nil
+}
+
+// SourceInfo describes where the staged input of an execution was taken from, when it is not the working copy.
+type SourceInfo struct {
+	Ref           string // Git reference as given by the user
+	Revision      string // Commit the reference resolved to
+	RepositoryURI string
+	Archive       string // Path of the archive the input was unpacked from
+	ArchiveSHA256 string
+}
+
+type inputKey struct {
+	path     string
+	ref      string
+	glob     string
+	excludes string
+	policy   InputPolicy
+}
+
+func inputKeyOf(exec *ToolExecution) inputKey {
+	return inputKey{
+		path:     exec.Path,
+		ref:      exec.Ref,
+		glob:     exec.Glob,
+		excludes: strings.Join(exec.Excludes, "\x00"),
+		policy:   exec.Input,
+	}
+}
+
+type stagedInput struct {
+	once    sync.Once
+	users   int
+	dir     string
+	skipped []SkippedFile
+	source  SourceInfo
+	err     error
+}
+
+// InputStager prepares the input directory of each distinct (path, glob, excludes, policy) combination once,
+// and shares it between every execution that uses it. Directories are removed when their last user releases them.
+type InputStager struct {
+	mutex  sync.Mutex
+	inputs map[inputKey]*stagedInput
+}
+
+func NewInputStager() *InputStager {
+	return &InputStager{
+		inputs: make(map[inputKey]*stagedInput),
+	}
+}
+
+// Acquire registers exec as a user of its input. Every execution must be acquired before the first one is
+// staged, otherwise an input might be removed and staged again in between.
+func (s *InputStager) Acquire(exec *ToolExecution) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := inputKeyOf(exec)
+	input, ok := s.inputs[key]
+
+	if !ok {
+		input = &stagedInput{}
+		s.inputs[key] = input
+	}
+
+	input.users++
+}
+
+// Stage returns the directory holding the input of exec, preparing it if no other execution did so yet.
+// The directory is shared and must not be modified. The files left out of the input and the origin of the input
+// are stored in exec. Executions of a single project share the input of the whole path, of which they only use the
+// directory of their project, created empty if none of its files were staged.
+func (s *InputStager) Stage(exec *ToolExecution) (string, error) {
+	s.mutex.Lock()
+	input, ok := s.inputs[inputKeyOf(exec)]
+	s.mutex.Unlock()
+
+	if !ok {
+		return "", fmt.Errorf("input of %s was not acquired", exec.Tool.Name)
+	}
+
+	input.once.Do(func() {
+		input.dir, input.skipped, input.source, input.err = stageInput(exec)
+	})
+
+	exec.Skipped = input.skipped
+	exec.Source = input.source
+
+	if exec.ProjectRoot != "" {
+		exec.Skipped = withinProject(input.skipped, exec.ProjectRoot)
+	}
+
+	if input.err == nil && exec.ProjectRoot != "" {
+		if err := os.MkdirAll(filepath.Join(input.dir, filepath.FromSlash(exec.ProjectRoot)), 0755); err != nil {
+			return input.dir, err
+		}
+	}
+
+	return input.dir, input.err
+}
+
+// withinProject returns the files of skipped under the project root.
+func withinProject(skipped []SkippedFile, root string) []SkippedFile {
+	var result []SkippedFile
+
+	for _, file := range skipped {
+		p := filepath.ToSlash(file.Path)
+
+		if p == root || strings.HasPrefix(p, root+"/") {
+			result = append(result, file)
+		}
+	}
+
+	return result
+}
+
+// unpackInput writes the files of path into a new temporary tree: the files of the git revision ref if it is not
+// empty, or the content of path if it is an archive. It returns an empty path for the other inputs, which are read
+// in place.
+func unpackInput(path string, ref string, limits ArchiveLimits) (string, SourceInfo, []SkippedFile, error) {
+	var source SourceInfo
+
+	switch {
+	case ref != "":
+		return exportGitTree(path, ref)
+	case isArchive(path):
+		source.Archive = path
+
+		sum, err := fileSHA256(path)
+
+		if err != nil {
+			return "", source, nil, err
+		}
+
+		source.ArchiveSHA256 = sum
+
+		dir, skipped, err := extractArchive(path, limits)
+
+		return dir, source, skipped, err
+	default:
+		return "", source, nil, nil
+	}
+}
+
+// UnpackInput writes the files of path, as they are in the git revision ref if it is not empty, or as unpacked
+// from path if it is an archive, into a new temporary directory that the caller must remove. It returns an empty
+// string if path is a directory read in place.
+func UnpackInput(path string, ref string, limits ArchiveLimits) (string, error) {
+	dir, _, _, err := unpackInput(path, ref, limits)
+
+	if err != nil && dir != "" {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	return dir, err
+}
+
+// stageInput prepares the input directory of exec. Inputs taken from a git revision or an archive are
+// unpacked into a temporary tree first, which then goes through the same filtering as a regular directory.
+func stageInput(exec *ToolExecution) (string, []SkippedFile, SourceInfo, error) {
+	treeDir, source, treeSkipped, err := unpackInput(exec.Path, exec.Ref, exec.Input.Archive)
+
+	if treeDir != "" {
+		defer os.RemoveAll(treeDir)
+	}
+
+	if err != nil {
+		return "", treeSkipped, source, err
+	}
+
+	if treeDir == "" {
+		dir, skipped, err := prepareInputDir(exec.Path, exec.Glob, exec.Excludes, exec.Input)
+		return dir, skipped, source, err
+	}
+
+	dir, skipped, err := prepareInputDir(treeDir, exec.Glob, exec.Excludes, exec.Input)
+
+	return dir, append(treeSkipped, skipped...), source, err
+}
+
+// Release unregisters exec as a user of its input, removing the input directory if exec was the last one.
+func (s *InputStager) Release(exec *ToolExecution) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := inputKeyOf(exec)
+	input, ok := s.inputs[key]
+
+	if !ok {
+		return
+	}
+
+	input.users--
+
+	if input.users > 0 {
+		return
+	}
+
+	delete(s.inputs, key)
+
+	if input.dir != "" {
+		os.RemoveAll(input.dir)
+	}
+}
@@ -0,0 +1,199 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/infragov-project/infrarun/internal/core/tools"
+)
+
+func TestCollectInputFiles(t *testing.T) {
+
+	outside := t.TempDir()
+	root := t.TempDir()
+
+	writeFile := func(path string, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeFile(filepath.Join(outside, "secret.txt"), "secret")
+	writeFile(filepath.Join(root, "main.tf"), "resource {}")
+	writeFile(filepath.Join(root, "big.tf"), "0123456789abcdef")
+	writeFile(filepath.Join(root, "module", "vars.tf"), "variable {}")
+	writeFile(filepath.Join(root, "plugin.bin"), "\x00\x01\x02")
+
+	if err := os.Symlink(filepath.Join(root, "module"), filepath.Join(root, "inner")); err != nil {
+		t.Skip("symbolic links not supported: ", err)
+	}
+
+	if err := os.Symlink(outside, filepath.Join(root, "outer")); err != nil {
+		t.Fatal(err)
+	}
+
+	type Test struct {
+		Name        string
+		Policy      InputPolicy
+		WantFiles   []string
+		WantSkipped []SkippedFile
+	}
+
+	tests := []Test{
+		{
+			Name:      "follow within root",
+			Policy:    InputPolicy{Symlinks: FollowSymlinksWithinRoot},
+			WantFiles: []string{"big.tf", "inner/vars.tf", "main.tf", "module/vars.tf", "plugin.bin"},
+			WantSkipped: []SkippedFile{
+				{Path: "outer", Reason: "symbolic link points outside of the input root"},
+			},
+		},
+		{
+			Name:      "skip",
+			Policy:    InputPolicy{Symlinks: SkipSymlinks},
+			WantFiles: []string{"big.tf", "main.tf", "module/vars.tf", "plugin.bin"},
+			WantSkipped: []SkippedFile{
+				{Path: "inner", Reason: "symbolic link"},
+				{Path: "outer", Reason: "symbolic link"},
+			},
+		},
+		{
+			Name:        "follow all",
+			Policy:      InputPolicy{Symlinks: FollowAllSymlinks},
+			WantFiles:   []string{"big.tf", "inner/vars.tf", "main.tf", "module/vars.tf", "outer/secret.txt", "plugin.bin"},
+			WantSkipped: nil,
+		},
+		{
+			Name:      "size and binary",
+			Policy:    InputPolicy{Symlinks: SkipSymlinks, MaxFileSize: 12, SkipBinary: true},
+			WantFiles: []string{"main.tf", "module/vars.tf"},
+			WantSkipped: []SkippedFile{
+				{Path: "big.tf", Reason: "file is larger than 12 bytes"},
+				{Path: "inner", Reason: "symbolic link"},
+				{Path: "outer", Reason: "symbolic link"},
+				{Path: "plugin.bin", Reason: "binary file"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			files, skipped, err := collectInputFiles(root, "**/*", nil, tt.Policy)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0)
+
+			for _, f := range files {
+				got = append(got, filepath.ToSlash(f.Rel))
+			}
+
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(tt.WantFiles, got) {
+				t.Errorf("got %#v, want %#v", got, tt.WantFiles)
+			} else if !reflect.DeepEqual(tt.WantSkipped, skipped) {
+				t.Errorf("got %#v, want %#v", skipped, tt.WantSkipped)
+			}
+		})
+
+	}
+
+}
+
+func TestStageProjects(t *testing.T) {
+
+	root := t.TempDir()
+
+	writeFile := func(path string, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeFile(filepath.Join(root, "envs", "prod", "main.tf"), "prod")
+	writeFile(filepath.Join(root, "envs", "dev", "main.tf"), "dev")
+	writeFile(filepath.Join(root, "envs", "dev", "big.tf"), "0123456789abcdef")
+
+	type Test struct {
+		Name        string
+		ProjectRoot string
+		WantSkipped []SkippedFile
+	}
+
+	tests := []Test{
+		{Name: "prod", ProjectRoot: "envs/prod"},
+		{Name: "dev", ProjectRoot: "envs/dev", WantSkipped: []SkippedFile{{Path: "envs/dev/big.tf", Reason: "file is larger than 12 bytes"}}},
+		{Name: "no files", ProjectRoot: "envs/test"},
+	}
+
+	stager := NewInputStager()
+	executions := make([]*ToolExecution, 0, len(tests))
+
+	for _, tt := range tests {
+		exec, err := NewToolExecution(&tools.ToolInstance{Name: "GLITCH"}, root, "**/*")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		exec.ProjectRoot = tt.ProjectRoot
+		exec.Input.MaxFileSize = 12
+
+		stager.Acquire(exec)
+		executions = append(executions, exec)
+	}
+
+	// The input of the whole path is staged once, for every project
+	shared := ""
+
+	for i, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			exec := executions[i]
+			dir, err := stager.Stage(exec)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if shared == "" {
+				shared = dir
+			} else if dir != shared {
+				t.Errorf("got input %s, want the shared input %s", dir, shared)
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(tt.ProjectRoot))); err != nil {
+				t.Errorf("got no directory for the project: %v", err)
+			}
+
+			if !reflect.DeepEqual(tt.WantSkipped, exec.Skipped) {
+				t.Errorf("got %#v, want %#v", exec.Skipped, tt.WantSkipped)
+			}
+		})
+
+	}
+
+	for _, exec := range executions {
+		stager.Release(exec)
+	}
+
+	if _, err := os.Stat(shared); !os.IsNotExist(err) {
+		t.Errorf("got input %s left after its release", shared)
+	}
+
+}
@@ -0,0 +1,147 @@
+package results
+
+import (
+	"slices"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// Values of the SARIF baselineState property.
+const (
+	BaselineNew       = "new"
+	BaselineUnchanged = "unchanged"
+	BaselineUpdated   = "updated"
+	BaselineAbsent    = "absent"
+)
+
+func isAbsent(res *sarif.Result) bool {
+	return res.BaselineState != nil && *res.BaselineState == BaselineAbsent
+}
+
+func messageText(res *sarif.Result) string {
+	if res.Message == nil || res.Message.Text == nil {
+		return ""
+	}
+
+	return *res.Message.Text
+}
+
+// ApplyBaseline compares report with baseline by fingerprint and sets the baselineState of every result in report.
+// Results only present in baseline are added to the run of report with the same automation id, or else the same
+// tool, as absent results.
+// Absent results of an earlier comparison are dropped from report first, and left out of baseline.
+// Results of both reports lacking a fingerprint get one, computed without source (see [AddFingerprints]).
+func ApplyBaseline(report *sarif.Report, baseline *sarif.Report) {
+	for _, run := range report.Runs {
+		run.Results = slices.DeleteFunc(run.Results, isAbsent)
+	}
+
+	AddFingerprints(report, nil)
+	AddFingerprints(baseline, nil)
+
+	type previous struct {
+		run     *sarif.Run
+		result  *sarif.Result
+		matched bool
+	}
+
+	index := make(map[string]*previous)
+	order := make([]*previous, 0)
+
+	for _, run := range baseline.Runs {
+		for _, res := range run.Results {
+			fp, _ := fingerprintOf(res)
+
+			if _, ok := index[fp]; ok || isAbsent(res) {
+				continue
+			}
+
+			p := &previous{run: run, result: res}
+			index[fp] = p
+			order = append(order, p)
+		}
+	}
+
+	for _, run := range report.Runs {
+		for _, res := range run.Results {
+			fp, _ := fingerprintOf(res)
+			state := BaselineNew
+
+			if p, ok := index[fp]; ok {
+				p.matched = true
+				state = BaselineUnchanged
+
+				if p.result.Level != res.Level || messageText(p.result) != messageText(res) {
+					state = BaselineUpdated
+				}
+			}
+
+			res.WithBaselineState(state)
+		}
+	}
+
+	for _, p := range order {
+		if p.matched {
+			continue
+		}
+
+		run := findRun(report, p.run)
+
+		if run == nil {
+			run = sarif.NewRun()
+			run.Tool = p.run.Tool
+			run.AutomationDetails = p.run.AutomationDetails
+			report.AddRun(run)
+		}
+
+		absent := *p.result
+		absent.WithBaselineState(BaselineAbsent)
+		absent.RuleIndex = run.GetRuleIndex(resultRuleID(&absent))
+		run.AddResult(&absent)
+	}
+}
+
+func runAutomationID(run *sarif.Run) string {
+	if run.AutomationDetails != nil && run.AutomationDetails.ID != nil {
+		return *run.AutomationDetails.ID
+	}
+
+	return ""
+}
+
+// findRun returns the run of report matching the baseline run previous: the one with the same automation id,
+// telling apart several runs of a tool, or else the first one of the same tool.
+func findRun(report *sarif.Report, previous *sarif.Run) *sarif.Run {
+	if id := runAutomationID(previous); id != "" {
+		for _, run := range report.Runs {
+			if runAutomationID(run) == id {
+				return run
+			}
+		}
+	}
+
+	tool := runToolName(previous)
+
+	for _, run := range report.Runs {
+		if runToolName(run) == tool {
+			return run
+		}
+	}
+
+	return nil
+}
+
+// CountByBaselineState returns how many results of report have the given baselineState.
+func CountByBaselineState(report *sarif.Report, state string) int {
+	count := 0
+
+	for _, run := range report.Runs {
+		for _, res := range run.Results {
+			if res.BaselineState != nil && *res.BaselineState == state {
+				count++
+			}
+		}
+	}
+
+	return count
+}
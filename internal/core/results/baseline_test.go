@@ -0,0 +1,123 @@
+package results
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func mapSourceReader(files map[string]string) SourceReader {
+	return func(path string) ([]byte, error) {
+		content, ok := files[path]
+
+		if !ok {
+			return nil, fmt.Errorf("no such file: %s", path)
+		}
+
+		return []byte(content), nil
+	}
+}
+
+func TestApplyBaseline(t *testing.T) {
+
+	before := mapSourceReader(map[string]string{
+		"main.tf": "resource \"a\" {\n  password = \"hunter2\"\n}\n\nresource \"b\" {\n  public = true\n}\n",
+	})
+
+	// Same code, moved two lines down and re-indented, with the second resource removed and a new one added
+	after := mapSourceReader(map[string]string{
+		"main.tf": "# comment\n\nresource \"a\" {\n    password = \"hunter2\"\n}\n\nresource \"c\" {\n  open = true\n}\n",
+	})
+
+	baseline := report.NewV210Report()
+	baseline.AddRun(newTestRun("checkov",
+		newTestResult("CKV_SECRET_6", "main.tf", 2),
+		newTestResult("CKV_PUBLIC", "main.tf", 6),
+	))
+	AddFingerprints(baseline, before)
+
+	current := report.NewV210Report()
+	current.AddRun(newTestRun("checkov",
+		newTestResult("CKV_SECRET_6", "./main.tf", 4),
+		newTestResult("CKV_OPEN", "main.tf", 8),
+	))
+	AddFingerprints(current, after)
+
+	ApplyBaseline(current, baseline)
+
+	results := current.Runs[0].Results
+
+	want := []string{BaselineUnchanged, BaselineNew, BaselineAbsent}
+
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+
+	for i, res := range results {
+		if res.BaselineState == nil || *res.BaselineState != want[i] {
+			t.Errorf("got baselineState %v for result %d, want %s", res.BaselineState, i, want[i])
+		}
+	}
+
+	if n := CountByBaselineState(current, BaselineNew); n != 1 {
+		t.Errorf("got %d new results, want 1", n)
+	}
+
+	// Absent results of the first comparison are not taken as new ones
+	ApplyBaseline(current, baseline)
+
+	if n := len(current.Runs[0].Results); n != len(want) {
+		t.Errorf("got %d results comparing again, want %d", n, len(want))
+	}
+
+	if n := CountByBaselineState(current, BaselineNew); n != 1 {
+		t.Errorf("got %d new results comparing again, want 1", n)
+	}
+
+	updated := report.NewV210Report()
+	updated.AddRun(newTestRun("checkov", newTestResult("CKV_SECRET_6", "main.tf", 4).WithLevel("error")))
+	AddFingerprints(updated, after)
+
+	ApplyBaseline(updated, baseline)
+
+	if state := updated.Runs[0].Results[0].BaselineState; state == nil || *state != BaselineUpdated {
+		t.Errorf("got baselineState %v, want %s", state, BaselineUpdated)
+	}
+}
+
+func TestApplyBaselineRuns(t *testing.T) {
+
+	read := mapSourceReader(map[string]string{
+		"main.tf": "resource \"a\" {\n  password = \"hunter2\"\n}\n",
+	})
+
+	// Two runs of the same tool, told apart by their automation id
+	newRuns := func(rep *sarif.Report, second ...*sarif.Result) {
+		first := newTestRun("GLITCH")
+		first.AutomationDetails = sarif.NewRunAutomationDetails().WithID("glitch-terraform/a")
+
+		last := newTestRun("GLITCH", second...)
+		last.AutomationDetails = sarif.NewRunAutomationDetails().WithID("glitch-terraform/b")
+
+		rep.AddRun(first).AddRun(last)
+	}
+
+	baseline := report.NewV210Report()
+	newRuns(baseline, newTestResult("sec_hard_pass", "main.tf", 2))
+	AddFingerprints(baseline, read)
+
+	current := report.NewV210Report()
+	newRuns(current)
+
+	ApplyBaseline(current, baseline)
+
+	if n := len(current.Runs[0].Results); n != 0 {
+		t.Errorf("got %d results in the first run, want 0", n)
+	}
+
+	if n := len(current.Runs[1].Results); n != 1 {
+		t.Errorf("got %d results in the second run, want 1", n)
+	}
+}
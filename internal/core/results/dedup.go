@@ -0,0 +1,284 @@
+package results
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+type DedupMode int
+
+const (
+	// DedupOff leaves the report untouched.
+	DedupOff DedupMode = iota
+	// DedupReport keeps every result, marking duplicates and listing corroborating tools in the primary result.
+	DedupReport
+	// DedupCollapse removes duplicates, keeping their locations as related locations of the primary result.
+	DedupCollapse
+)
+
+var dedupModeNames = map[DedupMode]string{
+	DedupOff:      "off",
+	DedupReport:   "report",
+	DedupCollapse: "collapse",
+}
+
+func (m DedupMode) String() string {
+	name, ok := dedupModeNames[m]
+
+	if !ok {
+		return fmt.Sprintf("DedupMode(%d)", int(m))
+	}
+
+	return name
+}
+
+func ParseDedupMode(name string) (DedupMode, error) {
+	for mode, n := range dedupModeNames {
+		if n == name {
+			return mode, nil
+		}
+	}
+
+	return DedupOff, fmt.Errorf("unknown deduplication mode: %s", name)
+}
+
+// RuleEquivalences maps rules to the name of the group of equivalent rules they belong to.
+type RuleEquivalences map[string]string
+
+// NewRuleEquivalences builds [RuleEquivalences] from named groups of rules, each written as "tool:rule".
+// Tool names are the driver names found in the report and are matched case insensitively.
+func NewRuleEquivalences(groups map[string][]string) (RuleEquivalences, error) {
+	equivalences := make(RuleEquivalences)
+
+	for group, rules := range groups {
+		for _, rule := range rules {
+			tool, id, ok := strings.Cut(rule, ":")
+
+			if !ok {
+				return nil, fmt.Errorf("rule %q of group %q is not in the tool:rule form", rule, group)
+			}
+
+			equivalences[ruleKey(tool, id)] = group
+		}
+	}
+
+	return equivalences, nil
+}
+
+func ruleKey(tool string, rule string) string {
+	return strings.ToLower(tool) + ":" + rule
+}
+
+// group returns the name of the group of equivalent rules containing the given rule.
+// Rules without an equivalence form a group of their own.
+func (e RuleEquivalences) group(tool string, rule string) string {
+	key := ruleKey(tool, rule)
+
+	if group, ok := e[key]; ok {
+		return "group:" + group
+	}
+
+	return "rule:" + key
+}
+
+// normalizeURI turns a result location URI into a clean relative path, so that the same file
+// reported by different tools compares equal.
+func normalizeURI(uri string) string {
+	if strings.HasPrefix(uri, "file://") {
+		if u, err := url.Parse(uri); err == nil {
+			uri = u.Path
+		}
+	}
+
+	cleaned := path.Clean(uri)
+
+	return strings.TrimPrefix(cleaned, "/")
+}
+
+func resultRuleID(res *sarif.Result) string {
+	if res.RuleID != nil {
+		return *res.RuleID
+	}
+
+	if res.Rule != nil && res.Rule.ID != nil {
+		return *res.Rule.ID
+	}
+
+	return ""
+}
+
+func runToolName(run *sarif.Run) string {
+	if run.Tool != nil && run.Tool.Driver != nil && run.Tool.Driver.Name != nil {
+		return *run.Tool.Driver.Name
+	}
+
+	return ""
+}
+
+func primaryPhysicalLocation(res *sarif.Result) *sarif.PhysicalLocation {
+	if len(res.Locations) == 0 || res.Locations[0].PhysicalLocation == nil {
+		return nil
+	}
+
+	return res.Locations[0].PhysicalLocation
+}
+
+func resultURI(res *sarif.Result) (string, bool) {
+	loc := primaryPhysicalLocation(res)
+
+	if loc == nil || loc.ArtifactLocation == nil || loc.ArtifactLocation.URI == nil {
+		return "", false
+	}
+
+	return *loc.ArtifactLocation.URI, true
+}
+
+// resultLines returns the first and last line of the primary location of res, or zeros if it has none.
+func resultLines(res *sarif.Result) (int, int) {
+	loc := primaryPhysicalLocation(res)
+
+	if loc == nil || loc.Region == nil || loc.Region.StartLine == nil {
+		return 0, 0
+	}
+
+	start := *loc.Region.StartLine
+	end := start
+
+	if loc.Region.EndLine != nil {
+		end = *loc.Region.EndLine
+	}
+
+	return start, end
+}
+
+// resultColumns returns the first and last column of the primary location of res, zero when not given.
+func resultColumns(res *sarif.Result) (int, int) {
+	loc := primaryPhysicalLocation(res)
+	start, end := 0, 0
+
+	if loc == nil || loc.Region == nil {
+		return start, end
+	}
+
+	if loc.Region.StartColumn != nil {
+		start = *loc.Region.StartColumn
+	}
+
+	if loc.Region.EndColumn != nil {
+		end = *loc.Region.EndColumn
+	}
+
+	return start, end
+}
+
+func setResultProperty(res *sarif.Result, key string, value any) {
+	if res.Properties == nil {
+		res.Properties = sarif.NewPropertyBag()
+	}
+
+	res.Properties.Add(key, value)
+}
+
+type located struct {
+	run    *sarif.Run
+	result *sarif.Result
+}
+
+// Deduplicate groups the results of report found at the same file, lines and columns by equivalent rules, possibly
+// from different tools. The first result of each group is kept as the primary one, and lists the tools and rules of
+// the others as corroborating evidence. How the other results are handled depends on mode. Results without a line
+// are never grouped, since nothing tells that they are about the same code.
+func Deduplicate(report *sarif.Report, mode DedupMode, equivalences RuleEquivalences) {
+	if mode == DedupOff {
+		return
+	}
+
+	groups := make(map[string][]located)
+	order := make([]string, 0)
+
+	for _, run := range report.Runs {
+		tool := runToolName(run)
+
+		for _, res := range run.Results {
+			uri, ok := resultURI(res)
+
+			if !ok {
+				continue
+			}
+
+			start, end := resultLines(res)
+
+			if start == 0 {
+				continue
+			}
+
+			startColumn, endColumn := resultColumns(res)
+
+			key := fmt.Sprintf("%s\x00%d:%d\x00%d:%d\x00%s", normalizeURI(uri), start, startColumn, end, endColumn, equivalences.group(tool, resultRuleID(res)))
+
+			if _, ok := groups[key]; !ok {
+				order = append(order, key)
+			}
+
+			groups[key] = append(groups[key], located{run: run, result: res})
+		}
+	}
+
+	removed := make(map[*sarif.Result]bool)
+
+	for _, key := range order {
+		group := groups[key]
+
+		if len(group) < 2 {
+			continue
+		}
+
+		primary := group[0]
+		corroborations := make([]map[string]string, 0, len(group)-1)
+
+		for _, dup := range group[1:] {
+			tool := runToolName(dup.run)
+			rule := resultRuleID(dup.result)
+
+			corroborations = append(corroborations, map[string]string{"tool": tool, "ruleId": rule})
+
+			switch mode {
+			case DedupReport:
+				setResultProperty(dup.result, "duplicateOf", map[string]string{
+					"tool":   runToolName(primary.run),
+					"ruleId": resultRuleID(primary.result),
+				})
+			case DedupCollapse:
+				for _, loc := range dup.result.Locations {
+					related := *loc
+					related.Message = sarif.NewTextMessage(fmt.Sprintf("Also reported by %s (%s)", tool, rule))
+					primary.result.RelatedLocations = append(primary.result.RelatedLocations, &related)
+				}
+
+				removed[dup.result] = true
+			}
+		}
+
+		setResultProperty(primary.result, "corroboratedBy", corroborations)
+	}
+
+	if len(removed) == 0 {
+		return
+	}
+
+	for _, run := range report.Runs {
+		kept := make([]*sarif.Result, 0, len(run.Results))
+
+		for _, res := range run.Results {
+			if !removed[res] {
+				kept = append(kept, res)
+			}
+		}
+
+		run.Results = kept
+	}
+}
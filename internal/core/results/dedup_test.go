@@ -0,0 +1,158 @@
+package results
+
+import (
+	"testing"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func newTestRun(tool string, results ...*sarif.Result) *sarif.Run {
+	run := sarif.NewRunWithInformationURI(tool, "https://example.com")
+
+	for _, res := range results {
+		run.AddResult(res)
+	}
+
+	return run
+}
+
+func newTestResult(rule string, uri string, line int) *sarif.Result {
+	return sarif.NewRuleResult(rule).
+		WithMessage(sarif.NewTextMessage(rule)).
+		AddLocation(sarif.NewLocationWithPhysicalLocation(
+			sarif.NewPhysicalLocation().
+				WithArtifactLocation(sarif.NewSimpleArtifactLocation(uri)).
+				WithRegion(sarif.NewSimpleRegion(line, line)),
+		))
+}
+
+func TestDeduplicate(t *testing.T) {
+
+	equivalences, err := NewRuleEquivalences(map[string][]string{
+		"hardcoded-secret": {"GLITCH:sec_hard_secr", "checkov:CKV_SECRET_6"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Test struct {
+		Name        string
+		Mode        DedupMode
+		WantResults []int
+	}
+
+	tests := []Test{
+		{Name: "off", Mode: DedupOff, WantResults: []int{2, 2}},
+		{Name: "report", Mode: DedupReport, WantResults: []int{2, 2}},
+		{Name: "collapse", Mode: DedupCollapse, WantResults: []int{2, 1}},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			rep := report.NewV210Report()
+
+			glitch := newTestRun("GLITCH",
+				newTestResult("sec_hard_secr", "file:///main.tf", 3),
+				newTestResult("sec_hard_secr", "file:///main.tf", 7),
+			)
+
+			checkov := newTestRun("checkov",
+				newTestResult("CKV_SECRET_6", "./main.tf", 3),
+				newTestResult("CKV_AWS_1", "./main.tf", 7),
+			)
+
+			rep.AddRun(glitch).AddRun(checkov)
+
+			Deduplicate(rep, tt.Mode, equivalences)
+
+			for i, run := range rep.Runs {
+				if len(run.Results) != tt.WantResults[i] {
+					t.Errorf("got %d results in run %d, want %d", len(run.Results), i, tt.WantResults[i])
+				}
+			}
+
+			primary := glitch.Results[0]
+			_, corroborated := propertyOf(primary, "corroboratedBy")
+
+			if corroborated != (tt.Mode != DedupOff) {
+				t.Errorf("got corroboratedBy %v, want %v", corroborated, tt.Mode != DedupOff)
+			}
+
+			if tt.Mode == DedupCollapse && len(primary.RelatedLocations) != 1 {
+				t.Errorf("got %d related locations, want 1", len(primary.RelatedLocations))
+			}
+
+			if _, corroborated := propertyOf(glitch.Results[1], "corroboratedBy"); corroborated {
+				t.Errorf("result with a different rule was deduplicated")
+			}
+		})
+
+	}
+
+}
+
+func TestDeduplicateLocations(t *testing.T) {
+
+	type Test struct {
+		Name    string
+		First   *sarif.Result
+		Second  *sarif.Result
+		WantDup bool
+	}
+
+	withColumns := func(res *sarif.Result, start int, end int) *sarif.Result {
+		res.Locations[0].PhysicalLocation.Region.WithStartColumn(start).WithEndColumn(end)
+
+		return res
+	}
+
+	tests := []Test{
+		{
+			Name:    "same region",
+			First:   withColumns(newTestResult("rule", "main.tf", 3), 1, 5),
+			Second:  withColumns(newTestResult("rule", "main.tf", 3), 1, 5),
+			WantDup: true,
+		},
+		{
+			Name:    "other columns",
+			First:   withColumns(newTestResult("rule", "main.tf", 3), 1, 5),
+			Second:  withColumns(newTestResult("rule", "main.tf", 3), 9, 12),
+			WantDup: false,
+		},
+		{
+			Name:    "no region",
+			First:   newTestResult("rule", "main.tf", 0),
+			Second:  newTestResult("rule", "main.tf", 0),
+			WantDup: false,
+		},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			rep := report.NewV210Report()
+			rep.AddRun(newTestRun("tool", tt.First, tt.Second))
+
+			Deduplicate(rep, DedupReport, RuleEquivalences{})
+
+			if _, dup := propertyOf(tt.Second, "duplicateOf"); dup != tt.WantDup {
+				t.Errorf("got duplicate %v, want %v", dup, tt.WantDup)
+			}
+		})
+
+	}
+
+}
+
+func propertyOf(res *sarif.Result, key string) (any, bool) {
+	if res.Properties == nil {
+		return nil, false
+	}
+
+	value, ok := res.Properties.Properties[key]
+
+	return value, ok
+}
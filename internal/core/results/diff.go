@@ -0,0 +1,139 @@
+package results
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// DiffFinding is a finding of either of the compared reports.
+type DiffFinding struct {
+	Tool        string `json:"tool"`
+	Rule        string `json:"rule"`
+	State       string `json:"state"` // baselineState of the finding: new, unchanged, updated or absent (fixed)
+	URI         string `json:"uri,omitempty"`
+	Line        int    `json:"line,omitempty"`
+	Level       string `json:"level,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// DiffSummary counts the findings of a rule of a tool by state.
+type DiffSummary struct {
+	Tool      string `json:"tool"`
+	Rule      string `json:"rule"`
+	New       int    `json:"new"`
+	Fixed     int    `json:"fixed"`
+	Updated   int    `json:"updated"`
+	Unchanged int    `json:"unchanged"`
+}
+
+// Diff is the comparison of two reports.
+type Diff struct {
+	Summary  []DiffSummary `json:"summary"`
+	Findings []DiffFinding `json:"findings"`
+	Report   *sarif.Report `json:"-"` // The after report with the baselineState of its results set, and fixed results as absent
+}
+
+func copyReport(report *sarif.Report) (*sarif.Report, error) {
+	content, err := json.Marshal(report)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sarif.FromBytes(content)
+}
+
+// CompareReports compares after with before by fingerprint, as done by [ApplyBaseline], without modifying them.
+// Findings are sorted by tool, rule, state and location.
+func CompareReports(before *sarif.Report, after *sarif.Report) (*Diff, error) {
+	baseline, err := copyReport(before)
+
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := copyReport(after)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ApplyBaseline(current, baseline)
+
+	diff := &Diff{
+		Summary:  make([]DiffSummary, 0),
+		Findings: make([]DiffFinding, 0),
+		Report:   current,
+	}
+
+	summaries := make(map[[2]string]*DiffSummary)
+
+	for _, run := range current.Runs {
+		tool := runToolName(run)
+
+		for _, res := range run.Results {
+			uri, hasURI := resultURI(res)
+			line, _ := resultLines(res)
+			fp, _ := fingerprintOf(res)
+
+			finding := DiffFinding{
+				Tool:        tool,
+				Rule:        resultRuleID(res),
+				State:       *res.BaselineState,
+				Line:        line,
+				Level:       res.Level,
+				Message:     messageText(res),
+				Fingerprint: fp,
+			}
+
+			if hasURI {
+				finding.URI = normalizeURI(uri)
+			}
+
+			diff.Findings = append(diff.Findings, finding)
+
+			key := [2]string{finding.Tool, finding.Rule}
+			summary, ok := summaries[key]
+
+			if !ok {
+				summary = &DiffSummary{Tool: finding.Tool, Rule: finding.Rule}
+				summaries[key] = summary
+			}
+
+			switch finding.State {
+			case BaselineNew:
+				summary.New++
+			case BaselineAbsent:
+				summary.Fixed++
+			case BaselineUpdated:
+				summary.Updated++
+			default:
+				summary.Unchanged++
+			}
+		}
+	}
+
+	for _, summary := range summaries {
+		diff.Summary = append(diff.Summary, *summary)
+	}
+
+	slices.SortFunc(diff.Summary, func(a, b DiffSummary) int {
+		return cmp.Or(cmp.Compare(a.Tool, b.Tool), cmp.Compare(a.Rule, b.Rule))
+	})
+
+	slices.SortStableFunc(diff.Findings, func(a, b DiffFinding) int {
+		return cmp.Or(
+			cmp.Compare(a.Tool, b.Tool),
+			cmp.Compare(a.Rule, b.Rule),
+			cmp.Compare(a.State, b.State),
+			cmp.Compare(a.URI, b.URI),
+			cmp.Compare(a.Line, b.Line),
+		)
+	})
+
+	return diff, nil
+}
@@ -0,0 +1,53 @@
+package results
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func TestCompareReports(t *testing.T) {
+
+	withFingerprint := func(res *sarif.Result, fp string) *sarif.Result {
+		res.PartialFingerprints = map[string]string{FingerprintKey: fp}
+		return res
+	}
+
+	before := report.NewV210Report()
+	before.AddRun(newTestRun("checkov",
+		withFingerprint(newTestResult("CKV_AWS_20", "main.tf", 3), "a"),
+		withFingerprint(newTestResult("CKV_AWS_21", "main.tf", 3), "b"),
+	))
+
+	after := report.NewV210Report()
+	after.AddRun(newTestRun("checkov",
+		withFingerprint(newTestResult("CKV_AWS_20", "main.tf", 5), "a"),
+		withFingerprint(newTestResult("CKV_AWS_20", "s3.tf", 1), "c"),
+	))
+
+	diff, err := CompareReports(before, after)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []DiffSummary{
+		{Tool: "checkov", Rule: "CKV_AWS_20", New: 1, Unchanged: 1},
+		{Tool: "checkov", Rule: "CKV_AWS_21", Fixed: 1},
+	}
+
+	if !reflect.DeepEqual(diff.Summary, want) {
+		t.Errorf("got %#v, want %#v", diff.Summary, want)
+	}
+
+	if got := CountByBaselineState(diff.Report, BaselineAbsent); got != 1 {
+		t.Errorf("got %d absent results in the report, want 1", got)
+	}
+
+	if len(after.Runs[0].Results) != 2 || after.Runs[0].Results[0].BaselineState != nil {
+		t.Errorf("the compared report was modified")
+	}
+
+}
@@ -0,0 +1,90 @@
+package results
+
+import (
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// FailedRunReport returns the report of a run of the given tool that failed before producing results.
+// Its single run has no results, and is meant to be completed with [AddInvocation] and [AddExecutionError].
+func FailedRunReport(toolName string) *sarif.Report {
+	run := sarif.NewRun()
+	run.Tool = sarif.NewTool()
+	run.Tool.Driver = sarif.NewToolComponent().WithName(toolName)
+
+	rep := report.NewV210Report()
+	rep.AddRun(run)
+
+	return rep
+}
+
+// AddExecutionError records err as an error notification of the last invocation of every run of report, adding
+// an unsuccessful invocation to runs without one.
+func AddExecutionError(report *sarif.Report, err error) {
+	for _, run := range report.Runs {
+		if len(run.Invocations) == 0 {
+			run.AddInvocation(sarif.NewInvocation().WithExecutionSuccessful(false))
+		}
+
+		run.Invocations[len(run.Invocations)-1].AddToolExecutionNotification(
+			sarif.NewNotification().
+				WithLevel("error").
+				WithMessage(sarif.NewTextMessage(err.Error())),
+		)
+	}
+}
+
+// FailedRun is a run of a report whose tool did not execute successfully.
+type FailedRun struct {
+	Tool         string
+	AutomationID string   // Automation id of the run, if any
+	Errors       []string // Messages of the error notifications of the run
+}
+
+// runFailure returns the messages of the error notifications of run, and whether it has an unsuccessful invocation.
+func runFailure(run *sarif.Run) ([]string, bool) {
+	unsuccessful := false
+	errors := make([]string, 0)
+
+	for _, inv := range run.Invocations {
+		if inv.ExecutionSuccessful == nil || *inv.ExecutionSuccessful {
+			continue
+		}
+
+		unsuccessful = true
+
+		for _, notification := range inv.ToolExecutionNotifications {
+			if notification.Level == "error" && notification.Message != nil && notification.Message.Text != nil {
+				errors = append(errors, *notification.Message.Text)
+			}
+		}
+	}
+
+	return errors, unsuccessful
+}
+
+// FailedRuns returns the runs of report with an unsuccessful invocation.
+func FailedRuns(report *sarif.Report) []FailedRun {
+	failed := make([]FailedRun, 0)
+
+	for _, run := range report.Runs {
+		errors, unsuccessful := runFailure(run)
+
+		if !unsuccessful {
+			continue
+		}
+
+		f := FailedRun{
+			Tool:   runToolName(run),
+			Errors: errors,
+		}
+
+		if run.AutomationDetails != nil && run.AutomationDetails.ID != nil {
+			f.AutomationID = *run.AutomationDetails.ID
+		}
+
+		failed = append(failed, f)
+	}
+
+	return failed
+}
@@ -0,0 +1,234 @@
+package results
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// FilteredResultsProperty is the run property counting the results removed by [ApplyFilters], by filter name.
+const FilteredResultsProperty = "filteredResults"
+
+// Filter selects the results kept in a report. Filters are composed with [AllOf], [AnyOf] and [Not].
+type Filter struct {
+	Name string // Name the results removed by the filter are counted under
+	Keep func(run *sarif.Run, res *sarif.Result) bool
+}
+
+// levelRanks orders the SARIF levels by severity.
+var levelRanks = map[string]int{"none": 0, "note": 1, "warning": 2, "error": 3}
+
+// ParseLevel checks that level is a SARIF level: none, note, warning or error.
+func ParseLevel(level string) (string, error) {
+	if _, ok := levelRanks[level]; !ok {
+		return "", fmt.Errorf("unknown level: %s", level)
+	}
+
+	return level, nil
+}
+
+// resultLevel returns the level of res, falling back to the default level of its rule, and to warning.
+func resultLevel(run *sarif.Run, res *sarif.Result) string {
+	if res.Level != "" {
+		return res.Level
+	}
+
+	rule := ruleOf(run, res)
+
+	if rule != nil && rule.DefaultConfiguration != nil && rule.DefaultConfiguration.Level != "" {
+		return rule.DefaultConfiguration.Level
+	}
+
+	return "warning"
+}
+
+// ruleOf returns the rule of res in the driver of run, if any.
+func ruleOf(run *sarif.Run, res *sarif.Result) *sarif.ReportingDescriptor {
+	if run.Tool == nil || run.Tool.Driver == nil {
+		return nil
+	}
+
+	id := resultRuleID(res)
+
+	for _, rule := range run.Tool.Driver.Rules {
+		if rule.ID != nil && *rule.ID == id {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+// MinLevel keeps the results with a level at least as severe as level.
+func MinLevel(level string) Filter {
+	return Filter{
+		Name: "min-level",
+		Keep: func(run *sarif.Run, res *sarif.Result) bool {
+			return levelRanks[resultLevel(run, res)] >= levelRanks[level]
+		},
+	}
+}
+
+// matchesRule tells whether the rule of res in run matches pattern, either a rule id glob or tool:rule, with the
+// tool matched case insensitively.
+func matchesRule(run *sarif.Run, res *sarif.Result, pattern string) bool {
+	if tool, rule, ok := strings.Cut(pattern, ":"); ok && strings.EqualFold(tool, runToolName(run)) {
+		pattern = rule
+	}
+
+	matched, err := path.Match(pattern, resultRuleID(res))
+
+	return err == nil && matched
+}
+
+// Rules keeps the results of the rules matching one of patterns, rule id globs such as CKV_AWS_* or tool:rule.
+func Rules(patterns ...string) Filter {
+	return Filter{
+		Name: "rule",
+		Keep: func(run *sarif.Run, res *sarif.Result) bool {
+			return slices.ContainsFunc(patterns, func(p string) bool { return matchesRule(run, res, p) })
+		},
+	}
+}
+
+// ExcludeRules removes the results of the rules matching one of patterns, written as for [Rules].
+func ExcludeRules(patterns ...string) Filter {
+	f := Not(Rules(patterns...))
+	f.Name = "exclude-rule"
+
+	return f
+}
+
+// Tools keeps the results of the given tools, matched case insensitively.
+func Tools(names ...string) Filter {
+	return Filter{
+		Name: "tool",
+		Keep: func(run *sarif.Run, res *sarif.Result) bool {
+			tool := runToolName(run)
+
+			return slices.ContainsFunc(names, func(name string) bool { return strings.EqualFold(name, tool) })
+		},
+	}
+}
+
+// Paths keeps the results in files matching one of globs, relative to the analyzed path.
+func Paths(globs ...string) Filter {
+	return Filter{
+		Name: "path",
+		Keep: func(run *sarif.Run, res *sarif.Result) bool {
+			uri, ok := resultURI(res)
+
+			if !ok {
+				return false
+			}
+
+			return slices.ContainsFunc(globs, func(glob string) bool {
+				matched, err := doublestar.PathMatch(glob, normalizeURI(uri))
+
+				return err == nil && matched
+			})
+		},
+	}
+}
+
+// Categories keeps the results classified with [AddTaxa] in one of the given taxonomy categories. CWE ids are
+// written as CWE-798.
+func Categories(ids ...string) Filter {
+	return Filter{
+		Name: "category",
+		Keep: func(run *sarif.Run, res *sarif.Result) bool {
+			for _, ref := range res.Taxa {
+				if ref.ID == nil {
+					continue
+				}
+
+				id := *ref.ID
+
+				if ref.ToolComponent != nil && ref.ToolComponent.Name != nil && *ref.ToolComponent.Name == CWETaxonomyName {
+					id = "CWE-" + id
+				}
+
+				if slices.ContainsFunc(ids, func(want string) bool { return strings.EqualFold(want, id) }) {
+					return true
+				}
+			}
+
+			return false
+		},
+	}
+}
+
+// AllOf keeps the results kept by every one of filters.
+func AllOf(filters ...Filter) Filter {
+	names := make([]string, 0, len(filters))
+
+	for _, f := range filters {
+		names = append(names, f.Name)
+	}
+
+	return Filter{
+		Name: strings.Join(names, "+"),
+		Keep: func(run *sarif.Run, res *sarif.Result) bool {
+			return !slices.ContainsFunc(filters, func(f Filter) bool { return !f.Keep(run, res) })
+		},
+	}
+}
+
+// AnyOf keeps the results kept by at least one of filters.
+func AnyOf(filters ...Filter) Filter {
+	names := make([]string, 0, len(filters))
+
+	for _, f := range filters {
+		names = append(names, f.Name)
+	}
+
+	return Filter{
+		Name: strings.Join(names, "|"),
+		Keep: func(run *sarif.Run, res *sarif.Result) bool {
+			return slices.ContainsFunc(filters, func(f Filter) bool { return f.Keep(run, res) })
+		},
+	}
+}
+
+// Not keeps the results removed by f.
+func Not(f Filter) Filter {
+	return Filter{
+		Name: "not-" + f.Name,
+		Keep: func(run *sarif.Run, res *sarif.Result) bool {
+			return !f.Keep(run, res)
+		},
+	}
+}
+
+// ApplyFilters removes from report the results not kept by every one of filters. The removed results of each run
+// are counted in its [FilteredResultsProperty] property, under the name of the first filter that removed them and
+// under "total".
+func ApplyFilters(report *sarif.Report, filters ...Filter) {
+	if len(filters) == 0 {
+		return
+	}
+
+	for _, run := range report.Runs {
+		kept := make([]*sarif.Result, 0, len(run.Results))
+		counts := map[string]int{"total": 0}
+
+		for _, res := range run.Results {
+			i := slices.IndexFunc(filters, func(f Filter) bool { return !f.Keep(run, res) })
+
+			if i < 0 {
+				kept = append(kept, res)
+				continue
+			}
+
+			counts[filters[i].Name]++
+			counts["total"]++
+		}
+
+		run.Results = kept
+		setRunProperty(run, FilteredResultsProperty, counts)
+	}
+}
@@ -0,0 +1,77 @@
+package results
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func TestApplyFilters(t *testing.T) {
+
+	newReport := func() *sarif.Report {
+		secret := newTestResult("CKV_SECRET_6", "modules/db/main.tf", 3).WithLevel("error")
+		secret.AddTaxa(taxonReference("798", 0, CWETaxonomyName, 1))
+
+		rep := report.NewV210Report()
+		rep.AddRun(newTestRun("checkov",
+			secret,
+			newTestResult("CKV_AWS_20", "main.tf", 1).WithLevel("note"),
+		))
+		rep.AddRun(newTestRun("KICS", newTestResult("public-bucket", "modules/s3/main.tf", 5)))
+
+		return rep
+	}
+
+	type Test struct {
+		Name    string
+		Filters []Filter
+		Want    []string
+	}
+
+	tests := []Test{
+		{Name: "none", Want: []string{"CKV_SECRET_6", "CKV_AWS_20", "public-bucket"}},
+		{Name: "min level", Filters: []Filter{MinLevel("warning")}, Want: []string{"CKV_SECRET_6", "public-bucket"}},
+		{Name: "rule glob", Filters: []Filter{Rules("CKV_*")}, Want: []string{"CKV_SECRET_6", "CKV_AWS_20"}},
+		{Name: "tool rule", Filters: []Filter{Rules("kics:*")}, Want: []string{"public-bucket"}},
+		{Name: "exclude rule", Filters: []Filter{ExcludeRules("CKV_AWS_20")}, Want: []string{"CKV_SECRET_6", "public-bucket"}},
+		{Name: "tool", Filters: []Filter{Tools("KICS")}, Want: []string{"public-bucket"}},
+		{Name: "path", Filters: []Filter{Paths("modules/**/*.tf")}, Want: []string{"CKV_SECRET_6", "public-bucket"}},
+		{Name: "category", Filters: []Filter{Categories("CWE-798")}, Want: []string{"CKV_SECRET_6"}},
+		{Name: "composed", Filters: []Filter{AnyOf(Tools("KICS"), Not(MinLevel("warning")))}, Want: []string{"CKV_AWS_20", "public-bucket"}},
+		{Name: "several", Filters: []Filter{Tools("checkov"), MinLevel("error")}, Want: []string{"CKV_SECRET_6"}},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			rep := newReport()
+
+			ApplyFilters(rep, tt.Filters...)
+
+			got := make([]string, 0)
+			removed := 0
+
+			for _, run := range rep.Runs {
+				for _, res := range run.Results {
+					got = append(got, resultRuleID(res))
+				}
+
+				if run.Properties != nil {
+					removed += run.Properties.Properties[FilteredResultsProperty].(map[string]int)["total"]
+				}
+			}
+
+			if !slices.Equal(got, tt.Want) {
+				t.Errorf("got %#v, want %#v", got, tt.Want)
+			}
+
+			if removed != 3-len(tt.Want) {
+				t.Errorf("got %d filtered results, want %d", removed, 3-len(tt.Want))
+			}
+		})
+
+	}
+
+}
@@ -0,0 +1,110 @@
+package results
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// Finding is a flat view of a result of a report, as shown by the output formats other than SARIF.
+type Finding struct {
+	Run              int // Index of the run of the result in the report
+	Tool             string
+	Rule             string
+	RuleName         string // Name or short description of the rule, if any
+	HelpURI          string
+	Level            string // Level of the result, falling back to the default level of its rule, and to warning
+	SecuritySeverity float64
+	Message          string
+	URI              string // Path of the file relative to the analyzed path, or the URI reported by the tool
+	StartLine        int
+	EndLine          int
+	StartColumn      int
+	Snippet          string
+	Fingerprint      string
+	BaselineState    string
+	Suppressed       bool
+}
+
+// propertyFloat returns the property key of properties as a number, whether it was written as a string or not.
+func propertyFloat(properties *sarif.PropertyBag, key string) (float64, bool) {
+	if properties == nil {
+		return 0, false
+	}
+
+	value, ok := properties.Properties[key]
+
+	if !ok {
+		return 0, false
+	}
+
+	f, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+
+	return f, err == nil
+}
+
+// Findings returns the results of every run of report as findings, in report order.
+func Findings(report *sarif.Report) []Finding {
+	findings := make([]Finding, 0)
+
+	for i, run := range report.Runs {
+		for _, res := range run.Results {
+			rule := ruleOf(run, res)
+			start, end := resultLines(res)
+			fp, _ := fingerprintOf(res)
+
+			f := Finding{
+				Run:         i,
+				Tool:        runToolName(run),
+				Rule:        resultRuleID(res),
+				Level:       resultLevel(run, res),
+				Message:     messageText(res),
+				StartLine:   start,
+				EndLine:     end,
+				Fingerprint: fp,
+				Suppressed:  len(res.Suppressions) > 0,
+			}
+
+			if uri, ok := resultURI(res); ok {
+				f.URI = normalizeURI(uri)
+			}
+
+			if res.BaselineState != nil {
+				f.BaselineState = *res.BaselineState
+			}
+
+			if loc := primaryPhysicalLocation(res); loc != nil && loc.Region != nil {
+				if loc.Region.StartColumn != nil {
+					f.StartColumn = *loc.Region.StartColumn
+				}
+
+				if loc.Region.Snippet != nil && loc.Region.Snippet.Text != nil {
+					f.Snippet = *loc.Region.Snippet.Text
+				}
+			}
+
+			if severity, ok := propertyFloat(res.Properties, SecuritySeverityProperty); ok {
+				f.SecuritySeverity = severity
+			} else if rule != nil {
+				f.SecuritySeverity, _ = propertyFloat(rule.Properties, SecuritySeverityProperty)
+			}
+
+			if rule != nil {
+				if rule.Name != nil {
+					f.RuleName = *rule.Name
+				} else if rule.ShortDescription != nil && rule.ShortDescription.Text != nil {
+					f.RuleName = *rule.ShortDescription.Text
+				}
+
+				if rule.HelpURI != nil {
+					f.HelpURI = *rule.HelpURI
+				}
+			}
+
+			findings = append(findings, f)
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,151 @@
+package results
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// FingerprintKey is the name of the partial fingerprint computed by infrarun for every result.
+const FingerprintKey = "infrarun/v1"
+
+// Lines of code hashed around each result, on each side, when computing its fingerprint.
+const fingerprintContextLines = 1
+
+// SourceReader returns the content of an analyzed file, given its path relative to the analyzed root.
+type SourceReader func(path string) ([]byte, error)
+
+// DirSourceReader returns a [SourceReader] for the files under dir. Paths escaping dir are refused.
+func DirSourceReader(dir string) SourceReader {
+	return func(p string) ([]byte, error) {
+		cleaned := path.Clean("/" + p)[1:]
+
+		if cleaned == "" {
+			return nil, fmt.Errorf("invalid source path: %q", p)
+		}
+
+		return os.ReadFile(filepath.Join(dir, filepath.FromSlash(cleaned)))
+	}
+}
+
+// sourceLines returns the lines between first and last (1-based, inclusive) of the file at uri, clamped to the
+// bounds of the file.
+func sourceLines(read SourceReader, uri string, first int, last int) ([]string, bool) {
+	if read == nil || uri == "" {
+		return nil, false
+	}
+
+	content, err := read(normalizeURI(uri))
+
+	if err != nil {
+		return nil, false
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+
+	first = max(first, 1)
+	last = min(last, len(lines))
+
+	if first > last {
+		return nil, false
+	}
+
+	return lines[first-1 : last], true
+}
+
+// codeHash hashes the code around the primary location of res, ignoring indentation and trailing spaces,
+// so the hash survives the code being moved. Without source, the snippet or the message of res is used.
+func codeHash(res *sarif.Result, read SourceReader) string {
+	var code string
+
+	uri, _ := resultURI(res)
+	start, end := resultLines(res)
+
+	if lines, ok := sourceLines(read, uri, start-fingerprintContextLines, end+fingerprintContextLines); ok && start > 0 {
+		for i, line := range lines {
+			lines[i] = strings.TrimSpace(line)
+		}
+
+		code = strings.Join(lines, "\n")
+	} else if loc := primaryPhysicalLocation(res); loc != nil && loc.Region != nil && loc.Region.Snippet != nil && loc.Region.Snippet.Text != nil {
+		code = strings.TrimSpace(*loc.Region.Snippet.Text)
+	} else if res.Message != nil && res.Message.Text != nil {
+		code = *res.Message.Text
+	}
+
+	sum := sha256.Sum256([]byte(code))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// detailHash hashes what tells apart results with the same code around them: their message, snippet and columns.
+func detailHash(res *sarif.Result) string {
+	var snippet string
+
+	loc := primaryPhysicalLocation(res)
+
+	if loc != nil && loc.Region != nil && loc.Region.Snippet != nil && loc.Region.Snippet.Text != nil {
+		snippet = strings.TrimSpace(*loc.Region.Snippet.Text)
+	}
+
+	start, end := resultColumns(res)
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d\x00%d", messageText(res), snippet, start, end)))
+
+	return hex.EncodeToString(sum[:4])
+}
+
+func fingerprintOf(res *sarif.Result) (string, bool) {
+	fp, ok := res.PartialFingerprints[FingerprintKey]
+
+	return fp, ok
+}
+
+// AddFingerprints sets the [FingerprintKey] partial fingerprint of every result of report lacking one. It is
+// built from the tool, the rule, the normalized path and a hash of the code around the result, read with read
+// (which may be nil). Results with the same fingerprint in a report are told apart by a suffix hashing their message,
+// snippet and columns, so that fixing one of them leaves the fingerprints of the others alone. Only results
+// identical in all of these are told apart by their order.
+func AddFingerprints(report *sarif.Report, read SourceReader) {
+	seen := make(map[string]bool)
+
+	for _, run := range report.Runs {
+		tool := strings.ToLower(runToolName(run))
+
+		for _, res := range run.Results {
+			if fp, ok := fingerprintOf(res); ok {
+				seen[fp] = true
+				continue
+			}
+
+			uri, _ := resultURI(res)
+
+			sum := sha256.Sum256([]byte(strings.Join([]string{tool, resultRuleID(res), normalizeURI(uri), codeHash(res, read)}, "\x00")))
+			fp := hex.EncodeToString(sum[:16])
+
+			if seen[fp] {
+				fp += ":" + detailHash(res)
+			}
+
+			base := fp
+
+			for n := 2; seen[fp]; n++ {
+				fp = fmt.Sprintf("%s:%d", base, n)
+			}
+
+			seen[fp] = true
+
+			if res.PartialFingerprints == nil {
+				res.PartialFingerprints = make(map[string]string)
+			}
+
+			res.PartialFingerprints[FingerprintKey] = fp
+		}
+	}
+}
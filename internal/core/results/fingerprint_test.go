@@ -0,0 +1,84 @@
+package results
+
+import (
+	"testing"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func TestAddFingerprints(t *testing.T) {
+
+	// Fingerprints are compared with the ones of older reports, so they must not change across versions
+	read := mapSourceReader(map[string]string{
+		"main.tf": "resource \"a\" {\n  password = \"hunter2\"\n}\n",
+	})
+
+	type Test struct {
+		Name string
+		Line int
+		Want string
+	}
+
+	tests := []Test{
+		{Name: "inside of the file", Line: 2, Want: "4b63f524cf3b3ae75eb37dc6355829c9"},
+		{Name: "last line", Line: 3, Want: "c5354b1d23511252000908ff32b6935c"},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			res := newTestResult("sec_hard_pass", "main.tf", tt.Line)
+			rep := report.NewV210Report()
+			rep.AddRun(newTestRun("GLITCH", res))
+
+			AddFingerprints(rep, read)
+
+			if got := res.PartialFingerprints[FingerprintKey]; got != tt.Want {
+				t.Errorf("got %#v, want %#v", got, tt.Want)
+			}
+		})
+
+	}
+
+}
+
+func TestAddFingerprintsDuplicates(t *testing.T) {
+
+	read := mapSourceReader(map[string]string{
+		"main.tf": "resource \"a\" {\n  password = \"hunter2\"\n}\n",
+	})
+
+	fingerprints := func(results ...*sarif.Result) []string {
+		rep := report.NewV210Report()
+		rep.AddRun(newTestRun("GLITCH", results...))
+
+		AddFingerprints(rep, read)
+
+		fps := make([]string, 0, len(results))
+
+		for _, res := range results {
+			fps = append(fps, res.PartialFingerprints[FingerprintKey])
+		}
+
+		return fps
+	}
+
+	first := newTestResult("sec_hard_pass", "main.tf", 2).WithMessage(sarif.NewTextMessage("first"))
+	second := newTestResult("sec_hard_pass", "main.tf", 2).WithMessage(sarif.NewTextMessage("second"))
+	third := newTestResult("sec_hard_pass", "main.tf", 2).WithMessage(sarif.NewTextMessage("third"))
+
+	all := fingerprints(first, second, third)
+
+	if all[0] == all[1] || all[1] == all[2] || all[0] == all[2] {
+		t.Errorf("got duplicate fingerprints %v", all)
+	}
+
+	// Fixing the second result leaves the fingerprint of the third alone
+	third.PartialFingerprints = nil
+	fixed := fingerprints(newTestResult("sec_hard_pass", "main.tf", 2).WithMessage(sarif.NewTextMessage("first")), third)
+
+	if fixed[1] != all[2] {
+		t.Errorf("got %#v, want %#v", fixed[1], all[2])
+	}
+}
@@ -0,0 +1,78 @@
+package results
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// ImageVersionLabel is the label of container images holding the version of the software they contain.
+const ImageVersionLabel = "org.opencontainers.image.version"
+
+// Invocation records how a tool was run, so that its results can be reproduced.
+type Invocation struct {
+	Image       string // Image reference, as given in the tool definition
+	ImageID     string
+	ImageDigest string // Repository digest of the image, if known
+	ImageLabels map[string]string
+	Cmd         []string
+	Start       time.Time
+	End         time.Time
+	ExitCode    int
+}
+
+func commandLine(cmd []string) string {
+	quoted := make([]string, 0, len(cmd))
+
+	for _, arg := range cmd {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$") {
+			arg = strconv.Quote(arg)
+		}
+
+		quoted = append(quoted, arg)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// AddInvocation records in every run of report how the tool was run: its command, image, start and end times and
+// exit code. The version of the tool is taken from the labels of the image if the tool did not report it.
+func AddInvocation(report *sarif.Report, inv Invocation, successful bool) {
+	for _, run := range report.Runs {
+		properties := sarif.NewPropertyBag()
+		properties.Add("image", inv.Image)
+
+		if inv.ImageID != "" {
+			properties.Add("imageId", inv.ImageID)
+		}
+
+		if inv.ImageDigest != "" {
+			properties.Add("imageDigest", inv.ImageDigest)
+		}
+
+		invocation := sarif.NewInvocation().
+			WithArguments(inv.Cmd).
+			WithCommandLine(commandLine(inv.Cmd)).
+			WithExecutionSuccessful(successful).
+			WithProperties(properties)
+
+		if !inv.Start.IsZero() {
+			invocation.WithStartTimeUtc(inv.Start.UTC().Format(time.RFC3339Nano))
+		}
+
+		if !inv.End.IsZero() {
+			invocation.WithEndTimeUtc(inv.End.UTC().Format(time.RFC3339Nano))
+			invocation.WithExitCode(inv.ExitCode)
+		}
+
+		run.AddInvocation(invocation)
+
+		version, ok := inv.ImageLabels[ImageVersionLabel]
+
+		if ok && version != "" && run.Tool != nil && run.Tool.Driver != nil && run.Tool.Driver.Version == nil {
+			run.Tool.Driver.WithVersion(version)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package results
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func TestAddInvocation(t *testing.T) {
+
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	inv := Invocation{
+		Image:       "checkmarx/kics:latest",
+		ImageDigest: "checkmarx/kics@sha256:0123",
+		ImageLabels: map[string]string{ImageVersionLabel: "2.1.3"},
+		Cmd:         []string{"scan", "-p", "/input", "--name", "a b"},
+		Start:       start,
+		End:         start.Add(time.Minute),
+		ExitCode:    50,
+	}
+
+	type Test struct {
+		Name        string
+		Version     string // Version reported by the tool itself
+		WantVersion string
+	}
+
+	tests := []Test{
+		{Name: "version from image", WantVersion: "2.1.3"},
+		{Name: "version from tool", Version: "2.1.4", WantVersion: "2.1.4"},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			run := sarif.NewRunWithInformationURI("KICS", "https://kics.io")
+
+			if tt.Version != "" {
+				run.Tool.Driver.WithVersion(tt.Version)
+			}
+
+			rep := report.NewV210Report()
+			rep.AddRun(run)
+
+			AddInvocation(rep, inv, true)
+
+			if got := *run.Tool.Driver.Version; got != tt.WantVersion {
+				t.Errorf("got version %#v, want %#v", got, tt.WantVersion)
+			}
+
+			if len(run.Invocations) != 1 {
+				t.Fatalf("got %d invocations, want 1", len(run.Invocations))
+			}
+
+			got := run.Invocations[0]
+
+			if want := `scan -p /input --name "a b"`; *got.CommandLine != want {
+				t.Errorf("got command line %#v, want %#v", *got.CommandLine, want)
+			}
+
+			if *got.ExitCode != 50 || !*got.ExecutionSuccessful {
+				t.Errorf("got exit code %d and success %v, want 50 and true", *got.ExitCode, *got.ExecutionSuccessful)
+			}
+
+			if want := "2026-03-01T10:01:00Z"; *got.EndTimeUtc != want {
+				t.Errorf("got end time %#v, want %#v", *got.EndTimeUtc, want)
+			}
+		})
+
+	}
+
+}
+
+func TestFailedRuns(t *testing.T) {
+
+	failed := FailedRunReport("KICS")
+	AddInvocation(failed, Invocation{Image: "checkmarx/kics:latest"}, false)
+	AddExecutionError(failed, fmt.Errorf("pull access denied"))
+	AddAutomationDetails(failed, "KICS/0123456789ab/envs/prod", map[string]any{})
+
+	succeeded := report.NewV210Report()
+	succeeded.AddRun(newTestRun("checkov", newTestResult("CKV_AWS_20", "main.tf", 1)))
+	AddInvocation(succeeded, Invocation{Image: "bridgecrew/checkov"}, true)
+
+	rep := MergeReports([]*sarif.Report{succeeded, failed})
+
+	got := FailedRuns(rep)
+	want := []FailedRun{{Tool: "KICS", AutomationID: "KICS/0123456789ab/envs/prod", Errors: []string{"pull access denied"}}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	if err := rep.Validate(); err != nil {
+		t.Errorf("invalid report: %v", err)
+	}
+
+}
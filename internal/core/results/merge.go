@@ -0,0 +1,88 @@
+package results
+
+import (
+	_ "embed"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/infragov-project/infrarun/internal/core/taxonomy"
+	"github.com/infragov-project/infrarun/internal/core/tools"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+//go:embed sarif-schema-2.1.0.json
+var sarifSchema []byte
+
+// ValidateReport checks content against the SARIF 2.1.0 schema, listing the violations found in the error.
+func ValidateReport(content []byte) error {
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(sarifSchema), gojsonschema.NewBytesLoader(content))
+
+	if err != nil {
+		return fmt.Errorf("not a SARIF report: %w", err)
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	violations := make([]string, 0, len(result.Errors()))
+
+	for _, violation := range result.Errors() {
+		violations = append(violations, violation.String())
+	}
+
+	return fmt.Errorf("invalid SARIF report: %s", strings.Join(violations, "; "))
+}
+
+// ParseReport validates content with [ValidateReport] and parses it.
+func ParseReport(content []byte) (*sarif.Report, error) {
+	err := ValidateReport(content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sarif.FromBytes(content)
+}
+
+// NormalizeExternalPaths makes the file locations of report, produced by a tool run outside of infrarun on the
+// files under root, relative to root against the [SrcRootBaseID] base id, as done by [ReplaceFilePaths] for the
+// tools run by infrarun. Relative URIs are taken as relative to root already.
+func NormalizeExternalPaths(report *sarif.Report, root string) error {
+	abs, err := filepath.Abs(root)
+
+	if err != nil {
+		return err
+	}
+
+	ReplaceFilePaths(report, &tools.ToolInstance{InputPath: filepath.ToSlash(abs)})
+
+	return nil
+}
+
+// MergeExternalReports merges reports, produced by infrarun or by other tools run on the files under root, into a
+// single report, keeping their order. Their file locations are normalized with [NormalizeExternalPaths], their
+// results fingerprinted and classified with t, and duplicated results handled according to mode. The runs of
+// reports are moved to the merged report.
+func MergeExternalReports(reports []*sarif.Report, root string, t *taxonomy.Taxonomy, mode DedupMode, equivalences RuleEquivalences) (*sarif.Report, error) {
+	read := DirSourceReader(root)
+
+	for _, rep := range reports {
+		err := NormalizeExternalPaths(rep, root)
+
+		if err != nil {
+			return nil, err
+		}
+
+		AddFingerprints(rep, read)
+	}
+
+	merged := MergeReports(reports)
+
+	AddTaxa(merged, t)
+	Deduplicate(merged, mode, equivalences)
+
+	return merged, nil
+}
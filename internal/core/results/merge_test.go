@@ -0,0 +1,116 @@
+package results
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func TestParseReport(t *testing.T) {
+
+	type Test struct {
+		Name    string
+		Content string
+		WantErr string
+	}
+
+	tests := []Test{
+		{Name: "valid", Content: `{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "CodeQL"}}, "results": []}]}`},
+		{Name: "not json", Content: `not json`, WantErr: "not a SARIF report"},
+		{Name: "no runs", Content: `{"version": "2.1.0"}`, WantErr: "runs is required"},
+		{Name: "wrong version", Content: `{"version": "2.0.0", "runs": []}`, WantErr: "version"},
+		{Name: "no driver", Content: `{"version": "2.1.0", "runs": [{"tool": {}}]}`, WantErr: "driver is required"},
+		{Name: "empty level", Content: `{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "x"}}, "results": [{"message": {"text": "m"}, "level": ""}]}]}`, WantErr: "level"},
+		{Name: "bad level", Content: `{"version": "2.1.0", "runs": [{"tool": {"driver": {"name": "x"}}, "results": [{"message": {"text": "m"}, "level": "high"}]}]}`, WantErr: "level"},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := ParseReport([]byte(tt.Content))
+
+			if tt.WantErr == "" && err != nil {
+				t.Errorf("got error %v", err)
+			}
+
+			if tt.WantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.WantErr)) {
+				t.Errorf("got %#v, want an error containing %#v", err, tt.WantErr)
+			}
+		})
+
+	}
+
+}
+
+func TestWriteReport(t *testing.T) {
+	run := sarif.NewRunWithInformationURI("KICS", "https://kics.io")
+	run.AddResult(sarif.NewRuleResult("a227ec01").
+		WithMessage(sarif.NewTextMessage("m")).
+		AddLocation(sarif.NewLocationWithPhysicalLocation(
+			sarif.NewPhysicalLocation().
+				WithArtifactLocation(sarif.NewSimpleArtifactLocation("main.tf")).
+				WithRegion(sarif.NewSimpleRegion(1, 1)),
+		)))
+
+	rep := report.NewV210Report()
+	rep.AddRun(run)
+
+	var b bytes.Buffer
+
+	err := WriteReport(&b, rep)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The reports written by infrarun can be merged
+	_, err = ParseReport(b.Bytes())
+
+	if err != nil {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestMergeExternalReports(t *testing.T) {
+	root := t.TempDir()
+
+	codeql := report.NewV210Report()
+	codeql.AddRun(newTestRun("CodeQL", newTestResult("tf/public-bucket", "file://"+filepath.ToSlash(root)+"/main.tf", 3)))
+
+	kics := report.NewV210Report()
+	kics.AddRun(newTestRun("KICS", newTestResult("public-bucket", "main.tf", 3)))
+
+	equivalences, err := NewRuleEquivalences(map[string][]string{"bucket": {"CodeQL:tf/public-bucket", "KICS:public-bucket"}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := MergeExternalReports([]*sarif.Report{codeql, kics}, root, nil, DedupCollapse, equivalences)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(merged.Runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(merged.Runs))
+	}
+
+	uri, _ := resultURI(merged.Runs[0].Results[0])
+
+	if uri != "main.tf" {
+		t.Errorf("got %#v, want %#v", uri, "main.tf")
+	}
+
+	if _, ok := fingerprintOf(merged.Runs[0].Results[0]); !ok {
+		t.Errorf("result was not fingerprinted")
+	}
+
+	if n := len(merged.Runs[1].Results); n != 0 {
+		t.Errorf("got %d results in the second run, want the duplicate collapsed", n)
+	}
+}
@@ -0,0 +1,207 @@
+package results
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/infragov-project/infrarun/internal/core/tools"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// SrcRootBaseID is the base id that the URIs of analyzed files are relative to, standing for the analyzed path.
+const SrcRootBaseID = "%SRCROOT%"
+
+type pathMapper struct {
+	tool  *tools.ToolInstance
+	root  string // Directory of the analyzed path mounted as the input of the tool
+	bases map[string]sarif.ArtifactLocation
+}
+
+// resolve returns the URI of loc, joined with the URI of its base id if the run defines it.
+func (m pathMapper) resolve(loc *sarif.ArtifactLocation) string {
+	uri := *loc.URI
+
+	if loc.URIBaseID == nil {
+		return uri
+	}
+
+	base, ok := m.bases[*loc.URIBaseID]
+
+	if !ok || base.URI == nil {
+		return uri
+	}
+
+	baseURL, err := url.Parse(*base.URI)
+
+	if err != nil {
+		return uri
+	}
+
+	ref, err := url.Parse(uri)
+
+	if err != nil {
+		return uri
+	}
+
+	return baseURL.ResolveReference(ref).String()
+}
+
+// cutInput returns rel, a path relative to the root of the container, relative to input instead.
+func cutInput(rel string, input string) (string, bool) {
+	switch {
+	case input == "":
+		return rel, true
+	case rel == input:
+		return ".", true
+	default:
+		return strings.CutPrefix(rel, input+"/")
+	}
+}
+
+// relativeToInput returns p relative to the input path of the tool, if it is under it. Relative paths going
+// through the input path, as ../../input/main.tf, are taken as relative to the root of the container, and other
+// relative paths as relative to the input path already.
+func (m pathMapper) relativeToInput(p string) (string, bool) {
+	input := strings.Trim(path.Clean("/"+m.tool.InputPath), "/")
+	cleaned := path.Clean(p)
+
+	if path.IsAbs(cleaned) {
+		return cutInput(strings.TrimPrefix(cleaned, "/"), input)
+	}
+
+	stripped := cleaned
+
+	for strings.HasPrefix(stripped, "../") {
+		stripped = strings.TrimPrefix(stripped, "../")
+	}
+
+	if rel, ok := cutInput(stripped, input); ok && input != "" {
+		return rel, true
+	}
+
+	if stripped != cleaned || cleaned == ".." {
+		return "", false // Goes up, but not into the input path
+	}
+
+	return cleaned, true
+}
+
+// hostPath returns the path, relative to the analyzed path, of the file at the given URI as reported by the tool.
+func (m pathMapper) hostPath(uri string) (string, bool) {
+	p := uri
+
+	if strings.HasPrefix(uri, "file:") {
+		u, err := url.Parse(uri)
+
+		if err != nil {
+			return "", false
+		}
+
+		p = u.Path
+	} else if unescaped, err := url.PathUnescape(uri); err == nil {
+		p = unescaped
+	}
+
+	// Transformations of the tool definition take precedence over the automatic mapping
+	if transformed, ok := m.tool.TransformPath(p); ok {
+		return strings.TrimPrefix(path.Clean(transformed), "/"), true
+	}
+
+	return m.relativeToInput(p)
+}
+
+func (m pathMapper) mapLocation(loc *sarif.ArtifactLocation) {
+	if loc == nil || loc.URI == nil {
+		return
+	}
+
+	rel, ok := m.hostPath(m.resolve(loc))
+
+	if !ok {
+		return
+	}
+
+	uri := (&url.URL{Path: path.Join(m.root, rel)}).String()
+
+	loc.URI = &uri
+	loc.WithURIBaseID(SrcRootBaseID)
+}
+
+func physicalArtifactLocation(loc *sarif.Location) *sarif.ArtifactLocation {
+	if loc == nil || loc.PhysicalLocation == nil {
+		return nil
+	}
+
+	return loc.PhysicalLocation.ArtifactLocation
+}
+
+// ReplaceFilePaths turns the URIs of the files reported by tool, as seen inside of its container, into paths
+// relative to the analyzed path, against the [SrcRootBaseID] base id. Absolute, relative and file:// URIs under
+// the input path of the tool are mapped automatically, while the path transformations of the tool definition,
+// if any matches, take precedence. URIs outside of the input path are left untouched.
+func ReplaceFilePaths(report *sarif.Report, tool *tools.ToolInstance) {
+	ReplaceProjectFilePaths(report, tool, "")
+}
+
+// ReplaceProjectFilePaths is like [ReplaceFilePaths], for a tool given the directory root of the analyzed path as
+// input: the paths of its files are prefixed with root.
+func ReplaceProjectFilePaths(report *sarif.Report, tool *tools.ToolInstance, root string) {
+	for _, run := range report.Runs {
+		m := pathMapper{tool: tool, root: root, bases: run.OriginalUriBaseIds}
+		locations := make([]*sarif.ArtifactLocation, 0)
+
+		for _, artifact := range run.Artifacts {
+			locations = append(locations, artifact.Location)
+		}
+
+		for _, res := range run.Results {
+			for _, loc := range res.Locations {
+				locations = append(locations, physicalArtifactLocation(loc))
+			}
+
+			for _, loc := range res.RelatedLocations {
+				locations = append(locations, physicalArtifactLocation(loc))
+			}
+
+			for _, fix := range res.Fixes {
+				for _, change := range fix.ArtifactChanges {
+					locations = append(locations, change.ArtifactLocation)
+				}
+			}
+		}
+
+		for _, loc := range locations {
+			m.mapLocation(loc)
+		}
+
+		// Base ids of the tool no longer used by any location point inside of its container
+		used := make(map[string]bool)
+
+		for _, loc := range locations {
+			if loc != nil && loc.URIBaseID != nil {
+				used[*loc.URIBaseID] = true
+			}
+		}
+
+		bases := make(map[string]sarif.ArtifactLocation)
+
+		for id, base := range run.OriginalUriBaseIds {
+			if used[id] {
+				bases[id] = base
+			}
+		}
+
+		if used[SrcRootBaseID] {
+			bases[SrcRootBaseID] = sarif.ArtifactLocation{
+				Description: sarif.NewTextMessage("The root of the analyzed files"),
+			}
+		}
+
+		if len(bases) > 0 {
+			run.OriginalUriBaseIds = bases
+		} else {
+			run.OriginalUriBaseIds = nil
+		}
+	}
+}
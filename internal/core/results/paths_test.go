@@ -0,0 +1,97 @@
+package results
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/infragov-project/infrarun/internal/core/tools"
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func TestReplaceFilePaths(t *testing.T) {
+
+	override := []tools.PathTransformation{{Pattern: *regexp.MustCompile("^/src/app"), Replacement: "app"}}
+	definitions := tools.GetEmbedToolDefinitions()
+
+	transformations := func(name string) []tools.PathTransformation {
+		tool := definitions[name]
+		instance, err := tool.DefaultInstance()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return instance.PathTransformations
+	}
+
+	kics := transformations("KICS")
+	checkov := transformations("checkov")
+
+	type Test struct {
+		Name            string
+		InputPath       string
+		Root            string
+		Transformations []tools.PathTransformation
+		URI             string
+		BaseID          string
+		BaseURI         string
+		Want            string
+		WantBaseID      string
+	}
+
+	tests := []Test{
+		{Name: "file uri", InputPath: "/input", URI: "file:///input/modules/main.tf", Want: "modules/main.tf", WantBaseID: SrcRootBaseID},
+		{Name: "absolute", InputPath: "/input", URI: "/input/main.tf", Want: "main.tf", WantBaseID: SrcRootBaseID},
+		{Name: "relative through input", InputPath: "/input", URI: "../../input/main.tf", Want: "main.tf", WantBaseID: SrcRootBaseID},
+		{Name: "relative from root", InputPath: "/input/", URI: "input/main.tf", Want: "main.tf", WantBaseID: SrcRootBaseID},
+		{Name: "relative to input", InputPath: "/input", URI: "modules/main.tf", Want: "modules/main.tf", WantBaseID: SrcRootBaseID},
+		{Name: "escaped", InputPath: "/input", URI: "file:///input/my%20module/main.tf", Want: "my%20module/main.tf", WantBaseID: SrcRootBaseID},
+		{Name: "outside of input", InputPath: "/input", URI: "file:///usr/lib/python3/site.py", Want: "file:///usr/lib/python3/site.py"},
+		{Name: "tool base id", InputPath: "/input", URI: "main.tf", BaseID: "SRC", BaseURI: "file:///input/modules/", Want: "modules/main.tf", WantBaseID: SrcRootBaseID},
+		{Name: "project root", InputPath: "/input", Root: "envs/prod", URI: "file:///input/modules/main.tf", Want: "envs/prod/modules/main.tf", WantBaseID: SrcRootBaseID},
+		{Name: "override", InputPath: "/input", Transformations: override, URI: "file:///src/app/main.tf", Want: "app/main.tf", WantBaseID: SrcRootBaseID},
+		{Name: "kics definition", InputPath: "/input", Transformations: kics, URI: "../../input/modules/main.tf", Want: "modules/main.tf", WantBaseID: SrcRootBaseID},
+		{Name: "checkov definition", InputPath: "/input", Transformations: checkov, URI: "input/main.tf", Want: "main.tf", WantBaseID: SrcRootBaseID},
+		{Name: "checkov definition, other directory", InputPath: "/input", Transformations: checkov, URI: "inputs/main.tf", Want: "inputs/main.tf", WantBaseID: SrcRootBaseID},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			res := newTestResult("rule", tt.URI, 1)
+			loc := res.Locations[0].PhysicalLocation.ArtifactLocation
+			run := newTestRun("tool", res)
+
+			if tt.BaseID != "" {
+				loc.WithURIBaseID(tt.BaseID)
+				run.WithOriginalUriBaseIds(map[string]sarif.ArtifactLocation{tt.BaseID: *sarif.NewSimpleArtifactLocation(tt.BaseURI)})
+			}
+
+			rep := report.NewV210Report()
+			rep.AddRun(run)
+
+			ReplaceProjectFilePaths(rep, &tools.ToolInstance{InputPath: tt.InputPath, PathTransformations: tt.Transformations}, tt.Root)
+
+			if *loc.URI != tt.Want {
+				t.Errorf("got %#v, want %#v", *loc.URI, tt.Want)
+			}
+
+			baseID := ""
+
+			if loc.URIBaseID != nil {
+				baseID = *loc.URIBaseID
+			}
+
+			if baseID != tt.WantBaseID {
+				t.Errorf("got base id %#v, want %#v", baseID, tt.WantBaseID)
+			}
+
+			if _, ok := run.OriginalUriBaseIds[tt.BaseID]; tt.BaseID != "" && ok {
+				t.Errorf("base id %s of the tool was kept", tt.BaseID)
+			}
+		})
+
+	}
+
+}
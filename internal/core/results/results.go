@@ -1,14 +1,16 @@
 package results
 
 import (
-	"net/url"
-	"strings"
+	"encoding/json"
+	"io"
+	"path"
+	"path/filepath"
 
-	"github.com/infragov-project/infrarun/internal/core/tools"
 	"github.com/owenrumney/go-sarif/v3/pkg/report"
 	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
 )
 
+// MergeReports returns a report with the runs of every report, in order.
 func MergeReports(reports []*sarif.Report) *sarif.Report {
 	merged := report.NewV210Report()
 
@@ -21,81 +23,125 @@ func MergeReports(reports []*sarif.Report) *sarif.Report {
 	return merged
 }
 
-func ReplaceFilePaths(report *sarif.Report, tool *tools.ToolInstance) {
-	for _, run := range report.Runs {
-
-		for _, base := range run.OriginalUriBaseIds {
-			if base.URI != nil {
-				processPath(base.URI, tool)
+// unsetStringProperties are the properties that the SARIF library writes as empty strings when they are not set.
+var unsetStringProperties = map[string]bool{"$schema": true, "kind": true, "level": true, "language": true, "importance": true}
+
+// dropUnsetProperties removes the properties of v that are null, or an empty string for the properties in
+// unsetStringProperties.
+func dropUnsetProperties(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, property := range value {
+			if property == nil || (unsetStringProperties[key] && property == "") {
+				delete(value, key)
+				continue
 			}
-		}
 
-		for _, artifact := range run.Artifacts {
-			if artifact.Location != nil && artifact.Location.URI != nil {
-				processPath(artifact.Location.URI, tool)
-			}
+			value[key] = dropUnsetProperties(property)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = dropUnsetProperties(item)
 		}
+	}
 
-		for _, res := range run.Results {
-			for _, loc := range res.Locations {
-				if loc.PhysicalLocation != nil && loc.PhysicalLocation.ArtifactLocation != nil && loc.PhysicalLocation.ArtifactLocation.URI != nil {
-					processPath(loc.PhysicalLocation.ArtifactLocation.URI, tool)
-				}
-			}
+	return v
+}
 
-			for _, relLoc := range res.RelatedLocations {
-				if relLoc.PhysicalLocation != nil && relLoc.PhysicalLocation.ArtifactLocation != nil && relLoc.PhysicalLocation.ArtifactLocation.URI != nil {
-					processPath(relLoc.PhysicalLocation.ArtifactLocation.URI, tool)
-				}
-			}
+// WriteReport writes report as indented JSON. Unlike the SARIF library, which writes the properties that are not
+// set as null or as empty strings, it leaves them out, so that the output is valid against the SARIF schema.
+func WriteReport(w io.Writer, report *sarif.Report) error {
+	content, err := json.Marshal(report)
 
-			for _, fix := range res.Fixes {
-				for _, change := range fix.ArtifactChanges {
-					if change.ArtifactLocation != nil && change.ArtifactLocation.URI != nil {
-						processPath(change.ArtifactLocation.URI, tool)
-					}
-				}
-			}
-		}
+	if err != nil {
+		return err
+	}
+
+	var document any
 
+	err = json.Unmarshal(content, &document)
+
+	if err != nil {
+		return err
 	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(dropUnsetProperties(document))
 }
 
-func processPath(path *string, tool *tools.ToolInstance) {
-	if path == nil {
-		return
+// AddVersionControlProvenance records, in every run of report, the repository and revision that the analyzed
+// files were taken from.
+func AddVersionControlProvenance(report *sarif.Report, repositoryURI string, revision string, ref string) {
+	for _, run := range report.Runs {
+		details := sarif.NewVersionControlDetails().
+			WithRepositoryURI(repositoryURI).
+			WithRevisionID(revision)
+
+		if ref != "" && ref != revision {
+			details.WithRevisionTag(ref)
+		}
+
+		run.VersionControlProvenance = append(run.VersionControlProvenance, details)
 	}
+}
 
-	if strings.HasPrefix(*path, "file://") {
-		u, err := url.Parse(*path)
+// AddArchiveProvenance records, in every run of report, the archive that the analyzed files were unpacked from.
+// File locations in such runs are relative to the root of the archive.
+func AddArchiveProvenance(report *sarif.Report, archive string, sha256 string) {
+	for _, run := range report.Runs {
+		setRunProperty(run, "archive", map[string]any{
+			"name":   path.Base(filepath.ToSlash(archive)),
+			"hashes": map[string]string{"sha-256": sha256},
+		})
+	}
+}
 
-		if err != nil {
-			return
+// AddAutomationDetails labels every run of report with id, the identifier of the execution that produced it, and
+// records the options the tool was run with.
+func AddAutomationDetails(report *sarif.Report, id string, options map[string]any) {
+	for _, run := range report.Runs {
+		if run.AutomationDetails == nil {
+			run.AutomationDetails = sarif.NewRunAutomationDetails()
 		}
 
-		decodedPath, err := url.PathUnescape(u.Path)
+		run.AutomationDetails.WithID(id)
 
-		if err != nil {
-			return
+		if run.AutomationDetails.Properties == nil {
+			run.AutomationDetails.Properties = sarif.NewPropertyBag()
 		}
 
-		u.Path = tool.ApplyPathTransformations(decodedPath)
-
-		*path = u.String()
-	} else {
-		*path = tool.ApplyPathTransformations(*path)
+		run.AutomationDetails.Properties.Add("options", options)
 	}
 }
 
-func GenerateFinalReport(reports map[*tools.ToolInstance]sarif.Report) *sarif.Report {
+// AddProjectRoot labels every run of report as the analysis of a single project of a monorepo, rooted at root.
+func AddProjectRoot(report *sarif.Report, root string) {
+	for _, run := range report.Runs {
+		if run.AutomationDetails == nil {
+			run.AutomationDetails = sarif.NewRunAutomationDetails()
+		}
+
+		if run.AutomationDetails.Properties == nil {
+			run.AutomationDetails.Properties = sarif.NewPropertyBag()
+		}
 
-	newReps := make([]*sarif.Report, 0)
+		run.AutomationDetails.Properties.Add("projectRoot", root)
+		setRunProperty(run, "projectRoot", root)
+	}
+}
 
-	for t, r := range reports {
-		ReplaceFilePaths(&r, t)
-		newReps = append(newReps, &r)
+func setRunProperty(run *sarif.Run, key string, value any) {
+	if run.Properties == nil {
+		run.Properties = sarif.NewPropertyBag()
 	}
 
-	return MergeReports(newReps)
+	run.Properties.Add(key, value)
+}
 
+// GenerateFinalReport merges the reports of every run, keeping their order. File paths are expected to be already
+// fixed with [ReplaceFilePaths].
+func GenerateFinalReport(reports []*sarif.Report) *sarif.Report {
+	return MergeReports(reports)
 }
@@ -0,0 +1,110 @@
+package results
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/infragov-project/infrarun/internal/core/tools"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// SecuritySeverityProperty is the property holding the security severity of results and rules, as a number from
+// 0.1 to 10 written as a string.
+const SecuritySeverityProperty = "security-severity"
+
+func findRule(run *sarif.Run, id string) *sarif.ReportingDescriptor {
+	if run.Tool == nil || run.Tool.Driver == nil {
+		return nil
+	}
+
+	for _, rule := range run.Tool.Driver.Rules {
+		if rule.ID != nil && *rule.ID == id {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+func propertyValue(properties *sarif.PropertyBag, name string) (string, bool) {
+	if properties == nil {
+		return "", false
+	}
+
+	value, ok := properties.Properties[name]
+
+	if !ok || value == nil {
+		return "", false
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// nativeSeverity returns the severity of res as reported by the tool, according to where m reads it from.
+func nativeSeverity(res *sarif.Result, rule *sarif.ReportingDescriptor, m tools.SeverityMap) (string, bool) {
+	switch m.From {
+	case tools.SeverityFromRule:
+		id := resultRuleID(res)
+		return id, id != ""
+	case tools.SeverityFromProperty:
+		if value, ok := propertyValue(res.Properties, m.Property); ok {
+			return value, true
+		}
+
+		if rule != nil {
+			return propertyValue(rule.Properties, m.Property)
+		}
+	}
+
+	return "", false
+}
+
+// NormalizeSeverity sets the level and security severity of every result of report from its native severity,
+// through m. Results whose native severity is not in m are left untouched. The security severity is also set
+// on the rule of the result, where consumers such as GitHub code scanning look for it.
+func NormalizeSeverity(report *sarif.Report, m tools.SeverityMap) {
+	if m.IsEmpty() {
+		return
+	}
+
+	for _, run := range report.Runs {
+		for _, res := range run.Results {
+			rule := findRule(run, resultRuleID(res))
+			value, ok := nativeSeverity(res, rule, m)
+
+			if !ok {
+				continue
+			}
+
+			severity, ok := m.Lookup(value, res.Level == "")
+
+			if !ok {
+				continue
+			}
+
+			res.Level = severity.Level
+
+			if severity.SecuritySeverity == 0 {
+				continue
+			}
+
+			securitySeverity := strconv.FormatFloat(severity.SecuritySeverity, 'f', 1, 64)
+			setResultProperty(res, SecuritySeverityProperty, securitySeverity)
+
+			if rule != nil {
+				if rule.Properties == nil {
+					rule.Properties = sarif.NewPropertyBag()
+				}
+
+				rule.Properties.Add(SecuritySeverityProperty, securitySeverity)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package results
+
+import (
+	"testing"
+
+	"github.com/infragov-project/infrarun/internal/core/tools"
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func TestNormalizeSeverity(t *testing.T) {
+
+	byRule := tools.SeverityMap{
+		From: tools.SeverityFromRule,
+		Levels: map[string]tools.Severity{
+			"*":            {Level: "warning"},
+			"CKV_SECRET_*": {Level: "error", SecuritySeverity: 9},
+			"CKV_SECRET_6": {Level: "note"},
+		},
+	}
+
+	byProperty := tools.SeverityMap{
+		From:     tools.SeverityFromProperty,
+		Property: "severity",
+		Levels: map[string]tools.Severity{
+			"HIGH": {Level: "error", SecuritySeverity: 8},
+			"LOW":  {Level: "note", SecuritySeverity: 3},
+		},
+	}
+
+	definitions := tools.GetEmbedToolDefinitions()
+
+	type Test struct {
+		Name                 string
+		Map                  tools.SeverityMap
+		Rule                 string
+		Level                string
+		Severity             string
+		WantLevel            string
+		WantSecuritySeverity any
+	}
+
+	tests := []Test{
+		{Name: "exact rule", Map: byRule, Rule: "CKV_SECRET_6", Level: "error", WantLevel: "note"},
+		{Name: "rule pattern", Map: byRule, Rule: "CKV_SECRET_2", Level: "warning", WantLevel: "error", WantSecuritySeverity: "9.0"},
+		{Name: "catch all", Map: byRule, Rule: "CKV_AWS_20", WantLevel: "warning"},
+		{Name: "catch all with a level of the tool", Map: byRule, Rule: "CKV_AWS_20", Level: "error", WantLevel: "error"},
+		{
+			Name:      "catch all overriding the levels of the tool",
+			Map:       byRule.Merge(tools.SeverityMap{OverrideLevels: true}),
+			Rule:      "CKV_AWS_20",
+			Level:     "error",
+			WantLevel: "warning",
+		},
+		{
+			Name:                 "shared glitch map",
+			Map:                  definitions["GLITCH-terraform"].Severity,
+			Rule:                 "sec_hard_pass",
+			WantLevel:            "error",
+			WantSecuritySeverity: "9.0",
+		},
+		{
+			Name:                 "checkov map",
+			Map:                  definitions["checkov"].Severity,
+			Rule:                 "CKV_AWS_20",
+			Level:                "error",
+			WantLevel:            "warning",
+			WantSecuritySeverity: "5.0",
+		},
+		{Name: "property", Map: byProperty, Rule: "a", Level: "warning", Severity: "High", WantLevel: "error", WantSecuritySeverity: "8.0"},
+		{Name: "unknown property value", Map: byProperty, Rule: "a", Level: "warning", Severity: "MEDIUM", WantLevel: "warning"},
+		{Name: "missing property", Map: byProperty, Rule: "a", Level: "warning", WantLevel: "warning"},
+		{
+			Name:                 "override",
+			Map:                  byProperty.Merge(tools.SeverityMap{Levels: map[string]tools.Severity{"LOW": {Level: "none"}}}),
+			Rule:                 "a",
+			Level:                "warning",
+			Severity:             "LOW",
+			WantLevel:            "none",
+			WantSecuritySeverity: nil,
+		},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			res := newTestResult(tt.Rule, "main.tf", 1)
+			res.Level = tt.Level
+
+			if tt.Severity != "" {
+				res.Properties = sarif.NewPropertyBag().Add("severity", tt.Severity)
+			}
+
+			rep := report.NewV210Report()
+			rep.AddRun(newTestRun("tool", res))
+
+			NormalizeSeverity(rep, tt.Map)
+
+			if res.Level != tt.WantLevel {
+				t.Errorf("got level %#v, want %#v", res.Level, tt.WantLevel)
+			}
+
+			if got, _ := propertyOf(res, SecuritySeverityProperty); got != tt.WantSecuritySeverity {
+				t.Errorf("got security severity %#v, want %#v", got, tt.WantSecuritySeverity)
+			}
+		})
+
+	}
+
+}
@@ -0,0 +1,186 @@
+package results
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// DefaultSnippetContextLines is the number of lines around results included in their context region by default.
+const DefaultSnippetContextLines = 2
+
+// snippetLines returns the lines between first and last of the file at uri as [sourceLines] does, without the empty
+// line following the newline at the end of the file.
+func snippetLines(read SourceReader, uri string, first int, last int) ([]string, bool) {
+	lines, ok := sourceLines(read, uri, first, last)
+
+	if ok && len(lines) > 1 && len(lines) < last-max(first, 1)+1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines, ok
+}
+
+func snippetRegion(lines []string, first int) *sarif.Region {
+	last := first + len(lines) - 1
+
+	return sarif.NewSimpleRegion(first, last).
+		WithSnippet(sarif.NewArtifactContent().WithText(strings.Join(lines, "\n")))
+}
+
+func addLocationSnippet(loc *sarif.Location, read SourceReader, contextLines int) {
+	if loc == nil || loc.PhysicalLocation == nil || loc.PhysicalLocation.Region == nil {
+		return
+	}
+
+	physical := loc.PhysicalLocation
+	region := physical.Region
+
+	if physical.ArtifactLocation == nil || physical.ArtifactLocation.URI == nil || region.StartLine == nil {
+		return
+	}
+
+	uri := *physical.ArtifactLocation.URI
+	start := *region.StartLine
+	end := start
+
+	if region.EndLine != nil {
+		end = *region.EndLine
+	}
+
+	if region.Snippet == nil {
+		if lines, ok := snippetLines(read, uri, start, end); ok {
+			region.WithSnippet(sarif.NewArtifactContent().WithText(strings.Join(lines, "\n")))
+		}
+	}
+
+	if physical.ContextRegion == nil {
+		first := max(start-contextLines, 1)
+
+		if lines, ok := snippetLines(read, uri, first, end+contextLines); ok {
+			physical.WithContextRegion(snippetRegion(lines, first))
+		}
+	}
+}
+
+// AddSnippets fills the snippet of the region of the locations of every result of report with the code read
+// with read, and their context region with contextLines lines around it. Snippets already set by the tool are
+// kept.
+func AddSnippets(report *sarif.Report, read SourceReader, contextLines int) {
+	if read == nil {
+		return
+	}
+
+	for _, run := range report.Runs {
+		for _, res := range run.Results {
+			for _, loc := range res.Locations {
+				addLocationSnippet(loc, read, contextLines)
+			}
+
+			for _, loc := range res.RelatedLocations {
+				addLocationSnippet(loc, read, contextLines)
+			}
+		}
+	}
+}
+
+// codeMessageTools are the tools whose result messages are the code the results were found at, by lowercase name.
+var codeMessageTools = map[string]bool{
+	"glitch": true,
+}
+
+// RedactSnippets removes every snippet and context region from the results of report, including the ones
+// set by the tools themselves, so that no analyzed code ends up in the report. The messages of tools that
+// report the code as message, as GLITCH, are replaced by the description of their rule, or its id.
+func RedactSnippets(report *sarif.Report) {
+	redact := func(loc *sarif.Location) {
+		if loc == nil || loc.PhysicalLocation == nil {
+			return
+		}
+
+		if loc.PhysicalLocation.Region != nil {
+			loc.PhysicalLocation.Region.Snippet = nil
+		}
+
+		loc.PhysicalLocation.ContextRegion = nil
+	}
+
+	for _, run := range report.Runs {
+		codeMessages := codeMessageTools[strings.ToLower(runToolName(run))]
+
+		for _, res := range run.Results {
+			if codeMessages {
+				message := resultRuleID(res)
+
+				if rule := ruleOf(run, res); rule != nil && rule.ShortDescription != nil && rule.ShortDescription.Text != nil {
+					message = *rule.ShortDescription.Text
+				}
+
+				res.WithMessage(sarif.NewTextMessage(message))
+			}
+
+			for _, loc := range res.Locations {
+				redact(loc)
+			}
+
+			for _, loc := range res.RelatedLocations {
+				redact(loc)
+			}
+		}
+	}
+}
+
+// AddArtifactHashes sets the sha-256 hash of every artifact of the runs of report, adding an artifact for each
+// file with results that has none. Files that cannot be read with read are left without hash.
+func AddArtifactHashes(report *sarif.Report, read SourceReader) {
+	if read == nil {
+		return
+	}
+
+	for _, run := range report.Runs {
+		listed := make(map[string]bool)
+
+		for _, artifact := range run.Artifacts {
+			if artifact.Location != nil && artifact.Location.URI != nil {
+				listed[normalizeURI(*artifact.Location.URI)] = true
+			}
+		}
+
+		for _, res := range run.Results {
+			uri, ok := resultURI(res)
+
+			if !ok || listed[normalizeURI(uri)] {
+				continue
+			}
+
+			location := sarif.NewSimpleArtifactLocation(uri)
+			location.URIBaseID = primaryPhysicalLocation(res).ArtifactLocation.URIBaseID
+
+			run.Artifacts = append(run.Artifacts, sarif.NewArtifact().WithLocation(location))
+			listed[normalizeURI(uri)] = true
+		}
+
+		for _, artifact := range run.Artifacts {
+			if artifact.Location == nil || artifact.Location.URI == nil {
+				continue
+			}
+
+			content, err := read(normalizeURI(*artifact.Location.URI))
+
+			if err != nil {
+				continue
+			}
+
+			sum := sha256.Sum256(content)
+
+			if artifact.Hashes == nil {
+				artifact.Hashes = make(map[string]string)
+			}
+
+			artifact.Hashes["sha-256"] = hex.EncodeToString(sum[:])
+			artifact.WithLength(len(content))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package results
+
+import (
+	"testing"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func TestAddSnippets(t *testing.T) {
+
+	read := mapSourceReader(map[string]string{
+		"main.tf": "resource \"a\" {\n  password = \"hunter2\"\n}\n",
+	})
+
+	type Test struct {
+		Name         string
+		Line         int
+		ContextLines int
+		ToolSnippet  string
+		WantSnippet  string
+		WantContext  string
+		WantStart    int
+	}
+
+	tests := []Test{
+		{Name: "no context", Line: 2, ContextLines: 0, WantSnippet: `  password = "hunter2"`, WantContext: `  password = "hunter2"`, WantStart: 2},
+		{Name: "context", Line: 2, ContextLines: 1, WantSnippet: `  password = "hunter2"`, WantContext: "resource \"a\" {\n  password = \"hunter2\"\n}", WantStart: 1},
+		{Name: "clamped context", Line: 1, ContextLines: 5, WantSnippet: `resource "a" {`, WantContext: "resource \"a\" {\n  password = \"hunter2\"\n}", WantStart: 1},
+		{Name: "tool snippet kept", Line: 2, ContextLines: 0, ToolSnippet: "password = ***", WantSnippet: "password = ***", WantContext: `  password = "hunter2"`, WantStart: 2},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			res := newTestResult("sec_hard_pass", "file:///main.tf", tt.Line)
+			region := res.Locations[0].PhysicalLocation.Region
+
+			if tt.ToolSnippet != "" {
+				region.WithSnippet(sarif.NewArtifactContent().WithText(tt.ToolSnippet))
+			}
+
+			rep := report.NewV210Report()
+			rep.AddRun(newTestRun("GLITCH", res))
+
+			AddSnippets(rep, read, tt.ContextLines)
+
+			if got := *region.Snippet.Text; got != tt.WantSnippet {
+				t.Errorf("got snippet %#v, want %#v", got, tt.WantSnippet)
+			}
+
+			context := res.Locations[0].PhysicalLocation.ContextRegion
+
+			if got := *context.Snippet.Text; got != tt.WantContext {
+				t.Errorf("got context %#v, want %#v", got, tt.WantContext)
+			}
+
+			if got := *context.StartLine; got != tt.WantStart {
+				t.Errorf("got context start %d, want %d", got, tt.WantStart)
+			}
+
+			AddArtifactHashes(rep, read)
+
+			artifacts := rep.Runs[0].Artifacts
+
+			if len(artifacts) != 1 || artifacts[0].Hashes["sha-256"] == "" {
+				t.Errorf("got artifacts %#v, want main.tf with its hash", artifacts)
+			}
+
+			// GLITCH reports the code as message
+			res.WithMessage(sarif.NewTextMessage(`password = "hunter2"`))
+			rep.Runs[0].AddRule("sec_hard_pass").WithDescription("Hard-coded password")
+
+			RedactSnippets(rep)
+
+			if region.Snippet != nil || res.Locations[0].PhysicalLocation.ContextRegion != nil {
+				t.Errorf("snippets left after redaction")
+			}
+
+			if got := *res.Message.Text; got != "Hard-coded password" {
+				t.Errorf("got message %#v, want %#v", got, "Hard-coded password")
+			}
+
+			if err := rep.Validate(); err != nil {
+				t.Errorf("invalid report: %v", err)
+			}
+		})
+
+	}
+
+}
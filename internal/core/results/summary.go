@@ -0,0 +1,165 @@
+package results
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// SummaryCount is the number of findings with a given key: a tool, a level or a file.
+type SummaryCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// RuleCount is the number of findings of a rule of a tool.
+type RuleCount struct {
+	Tool  string `json:"tool"`
+	Rule  string `json:"rule"`
+	Count int    `json:"count"`
+}
+
+// RunSummary is the outcome of a run of a report.
+type RunSummary struct {
+	Tool         string   `json:"tool"`
+	AutomationID string   `json:"automationId,omitempty"`
+	Findings     int      `json:"findings"`
+	Duration     float64  `json:"durationSeconds"` // Zero if the run has no start and end times
+	Successful   bool     `json:"successful"`
+	Errors       []string `json:"errors,omitempty"`
+}
+
+// Summary counts the findings of a report. Suppressed findings, and findings absent since the baseline, are only
+// counted in Suppressed and Absent.
+type Summary struct {
+	Findings   int            `json:"findings"`
+	Suppressed int            `json:"suppressed"`
+	Absent     int            `json:"absent"`
+	ByTool     []SummaryCount `json:"byTool"`
+	ByLevel    []SummaryCount `json:"byLevel"`
+	ByRule     []RuleCount    `json:"byRule"`
+	TopFiles   []SummaryCount `json:"topFiles"`
+	Runs       []RunSummary   `json:"runs"`
+	Failed     int            `json:"failed"`
+}
+
+// sortedCounts returns counts sorted by decreasing count, then by key.
+func sortedCounts(counts map[string]int) []SummaryCount {
+	sorted := make([]SummaryCount, 0, len(counts))
+
+	for key, count := range counts {
+		sorted = append(sorted, SummaryCount{Key: key, Count: count})
+	}
+
+	slices.SortFunc(sorted, func(a, b SummaryCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Key, b.Key))
+	})
+
+	return sorted
+}
+
+// runDuration returns the time between the earliest start and the latest end of the invocations of run.
+func runDuration(run *sarif.Run) time.Duration {
+	var start, end time.Time
+
+	for _, inv := range run.Invocations {
+		if inv.StartTimeUtc != nil {
+			if t, err := time.Parse(time.RFC3339Nano, *inv.StartTimeUtc); err == nil && (start.IsZero() || t.Before(start)) {
+				start = t
+			}
+		}
+
+		if inv.EndTimeUtc != nil {
+			if t, err := time.Parse(time.RFC3339Nano, *inv.EndTimeUtc); err == nil && t.After(end) {
+				end = t
+			}
+		}
+	}
+
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+
+	return end.Sub(start)
+}
+
+// Summarize counts the findings of report by tool, level, rule and file, keeping the topFiles files with the most
+// findings (every file if topFiles is negative), and lists the duration and outcome of its runs.
+func Summarize(report *sarif.Report, topFiles int) *Summary {
+	summary := &Summary{
+		ByRule: make([]RuleCount, 0),
+		Runs:   make([]RunSummary, 0, len(report.Runs)),
+	}
+
+	byTool := make(map[string]int)
+	byLevel := make(map[string]int)
+	byRule := make(map[[2]string]int)
+	byFile := make(map[string]int)
+
+	for _, run := range report.Runs {
+		tool := runToolName(run)
+		findings := 0
+
+		for _, res := range run.Results {
+			switch {
+			case res.BaselineState != nil && *res.BaselineState == BaselineAbsent:
+				summary.Absent++
+				continue
+			case len(res.Suppressions) > 0:
+				summary.Suppressed++
+				continue
+			}
+
+			findings++
+			byTool[tool]++
+			byLevel[resultLevel(run, res)]++
+			byRule[[2]string{tool, resultRuleID(res)}]++
+
+			if uri, ok := resultURI(res); ok {
+				byFile[normalizeURI(uri)]++
+			}
+		}
+
+		summary.Findings += findings
+
+		errors, unsuccessful := runFailure(run)
+
+		r := RunSummary{
+			Tool:       tool,
+			Findings:   findings,
+			Duration:   runDuration(run).Seconds(),
+			Successful: !unsuccessful,
+		}
+
+		if unsuccessful {
+			summary.Failed++
+			r.Errors = errors
+		}
+
+		if run.AutomationDetails != nil && run.AutomationDetails.ID != nil {
+			r.AutomationID = *run.AutomationDetails.ID
+		}
+
+		summary.Runs = append(summary.Runs, r)
+	}
+
+	summary.ByTool = sortedCounts(byTool)
+	summary.ByLevel = sortedCounts(byLevel)
+	summary.TopFiles = sortedCounts(byFile)
+
+	if topFiles >= 0 && len(summary.TopFiles) > topFiles {
+		summary.TopFiles = summary.TopFiles[:topFiles]
+	}
+
+	for key, count := range byRule {
+		summary.ByRule = append(summary.ByRule, RuleCount{Tool: key[0], Rule: key[1], Count: count})
+	}
+
+	slices.SortFunc(summary.ByRule, func(a, b RuleCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Tool, b.Tool), cmp.Compare(a.Rule, b.Rule))
+	})
+
+	return summary
+}
@@ -0,0 +1,65 @@
+package results
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func TestSummarize(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	suppressed := newTestResult("CKV_AWS_20", "main.tf", 1)
+	suppressed.AddSuppression(sarif.NewSuppression().WithKind(SuppressionInSource))
+
+	checkov := report.NewV210Report()
+	checkov.AddRun(newTestRun("checkov",
+		newTestResult("CKV_AWS_20", "main.tf", 3).WithLevel("error"),
+		newTestResult("CKV_AWS_20", "modules/s3/main.tf", 3).WithLevel("error"),
+		newTestResult("CKV_SECRET_6", "main.tf", 9).WithLevel("note"),
+		suppressed,
+	))
+
+	AddInvocation(checkov, Invocation{Start: start, End: start.Add(90 * time.Second)}, true)
+
+	kics := FailedRunReport("KICS")
+	AddExecutionError(kics, errors.New("image not found"))
+
+	rep := MergeReports([]*sarif.Report{checkov, kics})
+
+	summary := Summarize(rep, 1)
+
+	if summary.Findings != 3 || summary.Suppressed != 1 || summary.Failed != 1 {
+		t.Errorf("got %d findings, %d suppressed, %d failed, want 3, 1 and 1", summary.Findings, summary.Suppressed, summary.Failed)
+	}
+
+	wantLevels := []SummaryCount{{Key: "error", Count: 2}, {Key: "note", Count: 1}}
+
+	if !slices.Equal(summary.ByLevel, wantLevels) {
+		t.Errorf("got %#v, want %#v", summary.ByLevel, wantLevels)
+	}
+
+	wantFiles := []SummaryCount{{Key: "main.tf", Count: 2}}
+
+	if !slices.Equal(summary.TopFiles, wantFiles) {
+		t.Errorf("got %#v, want %#v", summary.TopFiles, wantFiles)
+	}
+
+	wantRule := RuleCount{Tool: "checkov", Rule: "CKV_AWS_20", Count: 2}
+
+	if summary.ByRule[0] != wantRule {
+		t.Errorf("got %#v, want %#v", summary.ByRule[0], wantRule)
+	}
+
+	if summary.Runs[0].Duration != 90 || !summary.Runs[0].Successful {
+		t.Errorf("got %#v, want a successful run of 90 seconds", summary.Runs[0])
+	}
+
+	if summary.Runs[1].Successful || !slices.Equal(summary.Runs[1].Errors, []string{"image not found"}) {
+		t.Errorf("got %#v, want a failed run", summary.Runs[1])
+	}
+}
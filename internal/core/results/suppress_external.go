@@ -0,0 +1,113 @@
+package results
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// ExternalSuppression accepts the results of a rule of a tool found in the files matching a path glob, or a single
+// result if it has a fingerprint, until it expires.
+type ExternalSuppression struct {
+	Tool        string // Matched case insensitively
+	Rule        string // "*" for every rule
+	Path        string // Glob matched against the URI of the result, empty for every file
+	Fingerprint string // Optional [FingerprintKey] fingerprint
+	Owner       string
+	Reason      string
+	Expires     time.Time // Last day the suppression applies
+}
+
+// Expired reports whether the last day of s is over at now. Days are UTC days, whatever the location of now.
+func (s ExternalSuppression) Expired(now time.Time) bool {
+	year, month, day := now.UTC().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	last := time.Date(s.Expires.Year(), s.Expires.Month(), s.Expires.Day(), 0, 0, 0, 0, time.UTC)
+
+	return today.After(last)
+}
+
+func (s ExternalSuppression) matches(tool string, res *sarif.Result) bool {
+	if !strings.EqualFold(s.Tool, tool) {
+		return false
+	}
+
+	if s.Rule != "*" && s.Rule != resultRuleID(res) {
+		return false
+	}
+
+	if s.Fingerprint != "" {
+		if fp, ok := fingerprintOf(res); !ok || fp != s.Fingerprint {
+			return false
+		}
+	}
+
+	if s.Path == "" {
+		return true
+	}
+
+	uri, ok := resultURI(res)
+
+	if !ok {
+		return false
+	}
+
+	matched, err := doublestar.PathMatch(s.Path, normalizeURI(uri))
+
+	return err == nil && matched
+}
+
+// ExpiredSuppressions returns the suppressions that no longer apply at now.
+func ExpiredSuppressions(suppressions []ExternalSuppression, now time.Time) []ExternalSuppression {
+	expired := make([]ExternalSuppression, 0)
+
+	for _, s := range suppressions {
+		if s.Expired(now) {
+			expired = append(expired, s)
+		}
+	}
+
+	return expired
+}
+
+// ApplyExternalSuppressions adds an external suppression to every result of report matched by one of suppressions
+// not expired at now. The first matching suppression is used.
+func ApplyExternalSuppressions(report *sarif.Report, suppressions []ExternalSuppression, now time.Time) {
+	active := make([]ExternalSuppression, 0, len(suppressions))
+
+	for _, s := range suppressions {
+		if !s.Expired(now) {
+			active = append(active, s)
+		}
+	}
+
+	if len(active) == 0 {
+		return
+	}
+
+	for _, run := range report.Runs {
+		tool := runToolName(run)
+
+		for _, res := range run.Results {
+			for _, s := range active {
+				if !s.matches(tool, res) {
+					continue
+				}
+
+				properties := sarif.NewPropertyBag()
+				properties.Add("owner", s.Owner)
+				properties.Add("expires", s.Expires.Format(time.DateOnly))
+
+				res.AddSuppression(sarif.NewSuppression().
+					WithKind(SuppressionExternal).
+					WithStatus("accepted").
+					WithJustification(s.Reason).
+					WithProperties(properties))
+
+				break
+			}
+		}
+	}
+}
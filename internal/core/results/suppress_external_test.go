@@ -0,0 +1,121 @@
+package results
+
+import (
+	"testing"
+	"time"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+)
+
+func TestApplyExternalSuppressions(t *testing.T) {
+
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	valid := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	type Test struct {
+		Name           string
+		Suppression    ExternalSuppression
+		WantSuppressed bool
+	}
+
+	tests := []Test{
+		{
+			Name:           "rule and path",
+			Suppression:    ExternalSuppression{Tool: "checkov", Rule: "CKV_AWS_20", Path: "modules/**/*.tf", Expires: valid},
+			WantSuppressed: true,
+		},
+		{
+			Name:           "every rule and file",
+			Suppression:    ExternalSuppression{Tool: "Checkov", Rule: "*", Expires: valid},
+			WantSuppressed: true,
+		},
+		{
+			Name:           "other path",
+			Suppression:    ExternalSuppression{Tool: "checkov", Rule: "CKV_AWS_20", Path: "envs/**", Expires: valid},
+			WantSuppressed: false,
+		},
+		{
+			Name:           "other tool",
+			Suppression:    ExternalSuppression{Tool: "KICS", Rule: "CKV_AWS_20", Expires: valid},
+			WantSuppressed: false,
+		},
+		{
+			Name:           "fingerprint",
+			Suppression:    ExternalSuppression{Tool: "checkov", Rule: "CKV_AWS_20", Fingerprint: "abc", Expires: valid},
+			WantSuppressed: true,
+		},
+		{
+			Name:           "other fingerprint",
+			Suppression:    ExternalSuppression{Tool: "checkov", Rule: "CKV_AWS_20", Fingerprint: "def", Expires: valid},
+			WantSuppressed: false,
+		},
+		{
+			Name:           "expires today",
+			Suppression:    ExternalSuppression{Tool: "checkov", Rule: "*", Expires: time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)},
+			WantSuppressed: true,
+		},
+		{
+			Name:           "expired",
+			Suppression:    ExternalSuppression{Tool: "checkov", Rule: "*", Expires: time.Date(2026, 6, 14, 0, 0, 0, 0, time.UTC)},
+			WantSuppressed: false,
+		},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			res := newTestResult("CKV_AWS_20", "file:///modules/s3/main.tf", 3)
+			res.PartialFingerprints = map[string]string{FingerprintKey: "abc"}
+
+			rep := report.NewV210Report()
+			rep.AddRun(newTestRun("checkov", res))
+
+			ApplyExternalSuppressions(rep, []ExternalSuppression{tt.Suppression}, now)
+
+			if got := len(res.Suppressions) > 0; got != tt.WantSuppressed {
+				t.Errorf("got suppressed %v, want %v", got, tt.WantSuppressed)
+			}
+
+			if tt.WantSuppressed && *res.Suppressions[0].Kind != SuppressionExternal {
+				t.Errorf("got kind %s, want %s", *res.Suppressions[0].Kind, SuppressionExternal)
+			}
+
+			if got := len(ExpiredSuppressions([]ExternalSuppression{tt.Suppression}, now)) > 0; got != tt.Suppression.Expired(now) {
+				t.Errorf("got expired %v, want %v", got, tt.Suppression.Expired(now))
+			}
+		})
+
+	}
+
+}
+
+func TestExternalSuppressionExpired(t *testing.T) {
+	ahead := time.FixedZone("UTC+14", 14*60*60)
+	behind := time.FixedZone("UTC-10", -10*60*60)
+
+	type Test struct {
+		Name    string
+		Expires time.Time
+		Now     time.Time
+		Want    bool
+	}
+
+	tests := []Test{
+		{Name: "last day", Expires: time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC), Now: time.Date(2026, 6, 15, 23, 59, 0, 0, time.UTC), Want: false},
+		{Name: "day after", Expires: time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC), Now: time.Date(2026, 6, 16, 0, 0, 0, 0, time.UTC), Want: true},
+		{Name: "local day after, UTC last day", Expires: time.Date(2026, 6, 14, 0, 0, 0, 0, time.UTC), Now: time.Date(2026, 6, 15, 8, 0, 0, 0, ahead), Want: false},
+		{Name: "local last day, UTC day after", Expires: time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC), Now: time.Date(2026, 6, 15, 20, 0, 0, 0, behind), Want: true},
+		{Name: "expiry in another location", Expires: time.Date(2026, 6, 15, 0, 0, 0, 0, ahead), Now: time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC), Want: false},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			got := ExternalSuppression{Expires: tt.Expires}.Expired(tt.Now)
+
+			if got != tt.Want {
+				t.Errorf("got %#v, want %#v", got, tt.Want)
+			}
+		})
+	}
+}
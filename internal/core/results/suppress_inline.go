@@ -0,0 +1,251 @@
+package results
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// Values of the kind of SARIF suppressions.
+const (
+	SuppressionInSource = "inSource"
+	SuppressionExternal = "external"
+)
+
+// A commentStyle is the syntax of a single line comment, with an optional closing token.
+type commentStyle struct {
+	Start string
+	End   string
+}
+
+var (
+	hashComment  = commentStyle{Start: "#"}
+	slashComment = commentStyle{Start: "//"}
+	dashComment  = commentStyle{Start: "--"}
+	xmlComment   = commentStyle{Start: "<!--", End: "-->"}
+)
+
+var commentStylesByExtension = map[string][]commentStyle{
+	".tf":         {hashComment, slashComment},
+	".tfvars":     {hashComment, slashComment},
+	".hcl":        {hashComment, slashComment},
+	".yml":        {hashComment},
+	".yaml":       {hashComment},
+	".py":         {hashComment},
+	".rb":         {hashComment},
+	".pp":         {hashComment},
+	".sh":         {hashComment},
+	".toml":       {hashComment},
+	".ini":        {hashComment},
+	".cfg":        {hashComment},
+	".conf":       {hashComment},
+	".properties": {hashComment},
+	".ps1":        {hashComment},
+	".bicep":      {slashComment},
+	".json":       {slashComment},
+	".jsonc":      {slashComment},
+	".js":         {slashComment},
+	".ts":         {slashComment},
+	".go":         {slashComment},
+	".java":       {slashComment},
+	".groovy":     {slashComment},
+	".cs":         {slashComment},
+	".sql":        {dashComment},
+	".lua":        {dashComment},
+	".xml":        {xmlComment},
+	".html":       {xmlComment},
+	".htm":        {xmlComment},
+	".config":     {xmlComment},
+}
+
+var commentStylesByName = map[string][]commentStyle{
+	"dockerfile":    {hashComment},
+	"containerfile": {hashComment},
+	"makefile":      {hashComment},
+	"vagrantfile":   {hashComment},
+	"jenkinsfile":   {slashComment},
+}
+
+// Styles tried on files of unknown types
+var defaultCommentStyles = []commentStyle{hashComment, slashComment, dashComment, xmlComment}
+
+func commentStylesFor(file string) []commentStyle {
+	base := strings.ToLower(path.Base(file))
+
+	if styles, ok := commentStylesByName[base]; ok {
+		return styles
+	}
+
+	// Dockerfile.prod, api.Dockerfile, ...
+	if strings.HasPrefix(base, "dockerfile.") || strings.HasSuffix(base, ".dockerfile") {
+		return []commentStyle{hashComment}
+	}
+
+	if styles, ok := commentStylesByExtension[path.Ext(base)]; ok {
+		return styles
+	}
+
+	return defaultCommentStyles
+}
+
+const ignoreDirectiveMarker = "infrarun:ignore"
+
+var ignoreDirectivePattern = regexp.MustCompile(`^infrarun:ignore\s+(\S+)(?:\s+(.*))?$`)
+
+type ignoreDirective struct {
+	Tool   string // Empty if the directive applies to every tool
+	Rule   string // "*" for every rule
+	Reason string
+	Line   int
+}
+
+func (d ignoreDirective) matches(tool string, rule string) bool {
+	if d.Tool != "" && !strings.EqualFold(d.Tool, tool) {
+		return false
+	}
+
+	return d.Rule == "*" || d.Rule == rule
+}
+
+// parseIgnoreComment returns the ignore directive in line, if it has a comment starting with one.
+// If commentOnly is set, the line must have nothing but the comment.
+func parseIgnoreComment(line string, styles []commentStyle, commentOnly bool) (ignoreDirective, bool) {
+	idx := strings.Index(line, ignoreDirectiveMarker)
+
+	if idx == -1 {
+		return ignoreDirective{}, false
+	}
+
+	before := strings.TrimRight(line[:idx], " \t")
+
+	for _, style := range styles {
+		if !strings.HasSuffix(before, style.Start) {
+			continue
+		}
+
+		if commentOnly && strings.TrimSpace(before) != style.Start {
+			continue
+		}
+
+		text := strings.TrimSpace(line[idx:])
+
+		if style.End != "" {
+			text = strings.TrimSpace(strings.TrimSuffix(text, style.End))
+		}
+
+		m := ignoreDirectivePattern.FindStringSubmatch(text)
+
+		if m == nil {
+			continue
+		}
+
+		directive := ignoreDirective{Rule: m[1], Reason: strings.TrimSpace(m[2])}
+
+		if tool, rule, ok := strings.Cut(m[1], ":"); ok {
+			directive.Tool = tool
+			directive.Rule = rule
+		}
+
+		return directive, true
+	}
+
+	return ignoreDirective{}, false
+}
+
+func isCommentLine(line string, styles []commentStyle) bool {
+	trimmed := strings.TrimSpace(line)
+
+	for _, style := range styles {
+		if strings.HasPrefix(trimmed, style.Start) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ignoreDirectivesAt returns the directives applying to the given line (1-based) of a file: the one in a comment at the
+// end of the line itself, and the ones in the block of comment lines right above it.
+func ignoreDirectivesAt(lines []string, line int, styles []commentStyle) []ignoreDirective {
+	directives := make([]ignoreDirective, 0)
+
+	if line < 1 || line > len(lines) {
+		return directives
+	}
+
+	if d, ok := parseIgnoreComment(lines[line-1], styles, false); ok {
+		d.Line = line
+		directives = append(directives, d)
+	}
+
+	for i := line - 1; i >= 1 && isCommentLine(lines[i-1], styles); i-- {
+		if d, ok := parseIgnoreComment(lines[i-1], styles, true); ok {
+			d.Line = i
+			directives = append(directives, d)
+		}
+	}
+
+	return directives
+}
+
+// ApplyInlineSuppressions looks for "infrarun:ignore <rule|tool:rule> [reason]" comments, in the syntax of the
+// type of each file, on the line of each result or in the comment lines right above it. Matching results are
+// kept, with an inSource suppression holding the reason as justification.
+func ApplyInlineSuppressions(report *sarif.Report, read SourceReader) {
+	if read == nil {
+		return
+	}
+
+	files := make(map[string][]string)
+
+	for _, run := range report.Runs {
+		tool := runToolName(run)
+
+		for _, res := range run.Results {
+			uri, ok := resultURI(res)
+			start, _ := resultLines(res)
+
+			if !ok || start == 0 {
+				continue
+			}
+
+			file := normalizeURI(uri)
+			lines, ok := files[file]
+
+			if !ok {
+				content, err := read(file)
+
+				if err == nil {
+					lines = strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+				}
+
+				files[file] = lines
+			}
+
+			for _, d := range ignoreDirectivesAt(lines, start, commentStylesFor(file)) {
+				if !d.matches(tool, resultRuleID(res)) {
+					continue
+				}
+
+				suppression := sarif.NewSuppression().
+					WithKind(SuppressionInSource).
+					WithStatus("accepted").
+					WithLocation(sarif.NewLocationWithPhysicalLocation(
+						sarif.NewPhysicalLocation().
+							WithArtifactLocation(sarif.NewSimpleArtifactLocation(uri)).
+							WithRegion(sarif.NewSimpleRegion(d.Line, d.Line)),
+					))
+
+				if d.Reason != "" {
+					suppression.WithJustification(d.Reason)
+				}
+
+				res.AddSuppression(suppression)
+
+				break
+			}
+		}
+	}
+}
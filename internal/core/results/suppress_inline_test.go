@@ -0,0 +1,84 @@
+package results
+
+import (
+	"testing"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+)
+
+func TestApplyInlineSuppressions(t *testing.T) {
+
+	read := mapSourceReader(map[string]string{
+		"main.tf": `resource "a" {
+  # infrarun:ignore sec_hard_secr test fixture, not a real secret
+  password = "hunter2"
+  user = "admin" // infrarun:ignore glitch:sec_hard_user
+  # infrarun:ignore KICS:* not for KICS
+  public = true
+}`,
+		"Dockerfile": `# infrarun:ignore sec_non_official_image vetted base image
+FROM someone/python
+# unrelated comment
+// infrarun:ignore sec_obsolete_command wrong comment syntax
+RUN wget http://example.com`,
+		"web.xml": `<!-- infrarun:ignore * legacy descriptor -->
+<web-app/>`,
+	})
+
+	type Test struct {
+		Name              string
+		Rule              string
+		URI               string
+		Line              int
+		WantSuppressed    bool
+		WantJustification string
+	}
+
+	tests := []Test{
+		{Name: "comment above", Rule: "sec_hard_secr", URI: "main.tf", Line: 3, WantSuppressed: true, WantJustification: "test fixture, not a real secret"},
+		{Name: "trailing comment with tool", Rule: "sec_hard_user", URI: "file:///main.tf", Line: 4, WantSuppressed: true},
+		{Name: "other tool", Rule: "sec_public", URI: "main.tf", Line: 6, WantSuppressed: false},
+		{Name: "other rule", Rule: "sec_other", URI: "main.tf", Line: 3, WantSuppressed: false},
+		{Name: "dockerfile", Rule: "sec_non_official_image", URI: "Dockerfile", Line: 2, WantSuppressed: true, WantJustification: "vetted base image"},
+		{Name: "wrong syntax", Rule: "sec_obsolete_command", URI: "Dockerfile", Line: 5, WantSuppressed: false},
+		{Name: "xml wildcard", Rule: "any", URI: "web.xml", Line: 2, WantSuppressed: true, WantJustification: "legacy descriptor"},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			res := newTestResult(tt.Rule, tt.URI, tt.Line)
+
+			rep := report.NewV210Report()
+			rep.AddRun(newTestRun("GLITCH", res))
+
+			ApplyInlineSuppressions(rep, read)
+
+			if (len(res.Suppressions) > 0) != tt.WantSuppressed {
+				t.Fatalf("got %d suppressions, want suppressed %v", len(res.Suppressions), tt.WantSuppressed)
+			}
+
+			if !tt.WantSuppressed {
+				return
+			}
+
+			s := res.Suppressions[0]
+
+			if s.Kind == nil || *s.Kind != SuppressionInSource {
+				t.Errorf("got kind %v, want %s", s.Kind, SuppressionInSource)
+			}
+
+			justification := ""
+
+			if s.Justification != nil {
+				justification = *s.Justification
+			}
+
+			if justification != tt.WantJustification {
+				t.Errorf("got justification %q, want %q", justification, tt.WantJustification)
+			}
+		})
+
+	}
+
+}
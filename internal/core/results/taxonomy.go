@@ -0,0 +1,155 @@
+package results
+
+import (
+	"strconv"
+
+	"github.com/infragov-project/infrarun/internal/core/taxonomy"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// CWETaxonomyName is the name of the taxonomy holding the CWE ids of the categories of results.
+const CWETaxonomyName = "CWE"
+
+// taxonomyComponent returns the index in run of the taxonomy with the given name, adding it with create if missing.
+func taxonomyComponent(run *sarif.Run, name string, create func() *sarif.ToolComponent) int {
+	for i, component := range run.Taxonomies {
+		if component.Name != nil && *component.Name == name {
+			return i
+		}
+	}
+
+	run.AddTaxonomie(create())
+
+	return len(run.Taxonomies) - 1
+}
+
+// taxonIndex returns the index of the taxon with the given id in component, adding it with create if missing.
+func taxonIndex(component *sarif.ToolComponent, id string, create func() *sarif.ReportingDescriptor) int {
+	for i, taxon := range component.Taxa {
+		if taxon.ID != nil && *taxon.ID == id {
+			return i
+		}
+	}
+
+	component.Taxa = append(component.Taxa, create())
+
+	return len(component.Taxa) - 1
+}
+
+func taxonReference(id string, index int, component string, componentIndex int) *sarif.ReportingDescriptorReference {
+	ref := sarif.NewReportingDescriptorReference()
+	ref.ID = &id
+	ref.Index = index
+	ref.ToolComponent = sarif.NewToolComponentReference().WithName(component)
+	ref.ToolComponent.Index = componentIndex
+
+	return ref
+}
+
+func hasTaxon(res *sarif.Result, component string, id string) bool {
+	for _, ref := range res.Taxa {
+		if ref.ID != nil && *ref.ID == id && ref.ToolComponent != nil && ref.ToolComponent.Name != nil && *ref.ToolComponent.Name == component {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddTaxa classifies every result of report with t. Results get a reference to each of their categories, and to
+// the CWE ids of those categories. The categories and CWE ids referenced by the results of a run are added to the
+// taxonomies of the run.
+func AddTaxa(report *sarif.Report, t *taxonomy.Taxonomy) {
+	if t == nil {
+		return
+	}
+
+	for _, run := range report.Runs {
+		tool := runToolName(run)
+
+		for _, res := range run.Results {
+			for _, category := range t.Classify(tool, resultRuleID(res)) {
+				addCategory(run, res, t, category)
+			}
+		}
+	}
+}
+
+func addCategory(run *sarif.Run, res *sarif.Result, t *taxonomy.Taxonomy, category taxonomy.Category) {
+	componentIndex := taxonomyComponent(run, t.Name, func() *sarif.ToolComponent {
+		component := sarif.NewToolComponent().WithName(t.Name)
+
+		if t.Version != "" {
+			component.WithVersion(t.Version)
+		}
+
+		if t.Description != "" {
+			component.WithShortDescription(sarif.NewMultiformatMessageString().WithText(t.Description))
+		}
+
+		return component
+	})
+
+	component := run.Taxonomies[componentIndex]
+
+	index := taxonIndex(component, category.ID, func() *sarif.ReportingDescriptor {
+		taxon := sarif.NewReportingDescriptor().WithID(category.ID)
+
+		if category.Name != "" {
+			taxon.WithName(category.Name)
+		}
+
+		if category.Description != "" {
+			taxon.WithShortDescription(sarif.NewMultiformatMessageString().WithText(category.Description))
+		}
+
+		return taxon
+	})
+
+	if !hasTaxon(res, t.Name, category.ID) {
+		res.AddTaxa(taxonReference(category.ID, index, t.Name, componentIndex))
+	}
+
+	if len(category.CWE) == 0 {
+		return
+	}
+
+	cweIndex := taxonomyComponent(run, CWETaxonomyName, func() *sarif.ToolComponent {
+		return sarif.NewToolComponent().
+			WithName(CWETaxonomyName).
+			WithOrganization("MITRE").
+			WithInformationURI("https://cwe.mitre.org/")
+	})
+
+	cweComponent := run.Taxonomies[cweIndex]
+	taxon := component.Taxa[index]
+
+	for _, cwe := range category.CWE {
+		id := strconv.Itoa(cwe)
+
+		cweTaxonIndex := taxonIndex(cweComponent, id, func() *sarif.ReportingDescriptor {
+			return sarif.NewReportingDescriptor().
+				WithID(id).
+				WithName("CWE-" + id).
+				WithHelpURI("https://cwe.mitre.org/data/definitions/" + id + ".html")
+		})
+
+		if !hasTaxon(res, CWETaxonomyName, id) {
+			res.AddTaxa(taxonReference(id, cweTaxonIndex, CWETaxonomyName, cweIndex))
+		}
+
+		related := false
+
+		for _, rel := range taxon.Relationships {
+			if rel.Target != nil && rel.Target.ID != nil && *rel.Target.ID == id {
+				related = true
+			}
+		}
+
+		if !related {
+			taxon.AddRelationship(sarif.NewReportingDescriptorRelationship().
+				WithTarget(taxonReference(id, cweTaxonIndex, CWETaxonomyName, cweIndex)).
+				WithKinds([]string{"relevant"}))
+		}
+	}
+}
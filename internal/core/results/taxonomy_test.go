@@ -0,0 +1,72 @@
+package results
+
+import (
+	"testing"
+
+	"github.com/infragov-project/infrarun/internal/core/taxonomy"
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+)
+
+func TestAddTaxa(t *testing.T) {
+
+	tax := taxonomy.Default().Extend([]taxonomy.Category{
+		{ID: "hard-coded-secret", Rules: map[string][]string{"KICS": {"487f4be7-*"}}},
+		{ID: "public-bucket", Name: "Public bucket", CWE: []int{284}, Rules: map[string][]string{"checkov": {"CKV_AWS_20"}}},
+	})
+
+	type Test struct {
+		Name string
+		Tool string
+		Rule string
+		Want []string // Ids of the referenced taxa
+	}
+
+	tests := []Test{
+		{Name: "default", Tool: "GLITCH", Rule: "sec_hard_secr", Want: []string{"hard-coded-secret", "798"}},
+		{Name: "pattern", Tool: "checkov", Rule: "CKV_SECRET_6", Want: []string{"hard-coded-secret", "798"}},
+		{Name: "kics query", Tool: "KICS", Rule: "a88baa34-e2ad-44ea-ad6f-8cac87bc7c71", Want: []string{"hard-coded-secret", "798"}},
+		{Name: "extended category", Tool: "kics", Rule: "487f4be7-3fd9", Want: []string{"hard-coded-secret", "798"}},
+		{Name: "new category", Tool: "checkov", Rule: "CKV_AWS_20", Want: []string{"public-bucket", "284"}},
+		{Name: "unclassified", Tool: "checkov", Rule: "CKV_AWS_21", Want: []string{}},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			res := newTestResult(tt.Rule, "main.tf", 1)
+			rep := report.NewV210Report()
+			rep.AddRun(newTestRun(tt.Tool, res))
+
+			AddTaxa(rep, tax)
+			AddTaxa(rep, tax) // Applying it again changes nothing
+
+			got := make([]string, 0)
+
+			for _, ref := range res.Taxa {
+				taxon := rep.Runs[0].Taxonomies[ref.ToolComponent.Index].Taxa[ref.Index]
+
+				if *taxon.ID != *ref.ID {
+					t.Errorf("reference to %s points to taxon %s", *ref.ID, *taxon.ID)
+				}
+
+				got = append(got, *ref.ID)
+			}
+
+			if len(got) != len(tt.Want) {
+				t.Fatalf("got %#v, want %#v", got, tt.Want)
+			}
+
+			for i := range got {
+				if got[i] != tt.Want[i] {
+					t.Errorf("got %#v, want %#v", got, tt.Want)
+				}
+			}
+
+			if err := rep.Validate(); err != nil {
+				t.Errorf("invalid report: %v", err)
+			}
+		})
+
+	}
+
+}
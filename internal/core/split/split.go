@@ -0,0 +1,118 @@
+package split
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type StrategyKind string
+
+const (
+	TerraformRoot  StrategyKind = "terraform-root"
+	AnsibleRole    StrategyKind = "ansible-role"
+	DirectoryDepth StrategyKind = "directory-depth"
+)
+
+// Strategy describes how project roots are recognized inside of a monorepo.
+type Strategy struct {
+	Kind  StrategyKind
+	Depth int // Only used by DirectoryDepth
+}
+
+func (s Strategy) String() string {
+	if s.Kind == DirectoryDepth {
+		return fmt.Sprintf("%s=%d", s.Kind, s.Depth)
+	}
+
+	return string(s.Kind)
+}
+
+// ParseStrategy parses "terraform-root", "ansible-role" or "directory-depth=N".
+func ParseStrategy(value string) (Strategy, error) {
+	name, arg, hasArg := strings.Cut(value, "=")
+
+	switch StrategyKind(name) {
+	case TerraformRoot, AnsibleRole:
+		if hasArg {
+			return Strategy{}, fmt.Errorf("split strategy %s takes no argument", name)
+		}
+
+		return Strategy{Kind: StrategyKind(name)}, nil
+	case DirectoryDepth:
+		depth, err := strconv.Atoi(arg)
+
+		if err != nil || depth < 1 {
+			return Strategy{}, fmt.Errorf("split strategy %s needs a positive depth, as in %s=2", name, name)
+		}
+
+		return Strategy{Kind: DirectoryDepth, Depth: depth}, nil
+	default:
+		return Strategy{}, fmt.Errorf("unknown split strategy: %s", value)
+	}
+}
+
+// isRoot reports whether dir, found depth levels below the base path, is a project root.
+func (s Strategy) isRoot(dir string, depth int, entries []os.DirEntry) bool {
+	switch s.Kind {
+	case TerraformRoot:
+		return slices.ContainsFunc(entries, func(e os.DirEntry) bool {
+			return !e.IsDir() && (strings.HasSuffix(e.Name(), ".tf") || strings.HasSuffix(e.Name(), ".tf.json"))
+		})
+	case AnsibleRole:
+		for _, main := range []string{"main.yml", "main.yaml"} {
+			if info, err := os.Stat(filepath.Join(dir, "tasks", main)); err == nil && info.Mode().IsRegular() {
+				return true
+			}
+		}
+
+		return false
+	case DirectoryDepth:
+		return depth == s.Depth
+	default:
+		return false
+	}
+}
+
+// FindRoots returns the project roots found under base, as slash separated paths relative to base, in
+// lexical order. Directories inside of a project root are not searched, so roots never nest. Hidden
+// directories (such as .git or .terraform) and symbolic links are never searched either.
+func FindRoots(base string, strategy Strategy) ([]string, error) {
+	roots := make([]string, 0)
+
+	var walk func(dir string, rel string, depth int) error
+
+	walk = func(dir string, rel string, depth int) error {
+		entries, err := os.ReadDir(dir)
+
+		if err != nil {
+			return err
+		}
+
+		if strategy.isRoot(dir, depth, entries) {
+			roots = append(roots, filepath.ToSlash(rel))
+			return nil
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+
+			if err := walk(filepath.Join(dir, entry.Name()), filepath.Join(rel, entry.Name()), depth+1); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := walk(base, ".", 0); err != nil {
+		return nil, err
+	}
+
+	return roots, nil
+}
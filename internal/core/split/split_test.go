@@ -0,0 +1,92 @@
+package split
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindRoots(t *testing.T) {
+
+	base := t.TempDir()
+
+	for _, file := range []string{
+		"main.tf",
+		"modules/network/main.tf",
+		"modules/network/nested/main.tf",
+		"modules/storage/variables.tf.json",
+		"modules/README.md",
+		"roles/web/tasks/main.yml",
+		"roles/db/tasks/main.yaml",
+		"roles/common/files/motd",
+		".terraform/modules/cached/main.tf",
+	} {
+		path := filepath.Join(base, filepath.FromSlash(file))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type Test struct {
+		Name     string
+		Strategy string
+		Path     string
+		Want     []string
+	}
+
+	tests := []Test{
+		{
+			Name:     "terraform root at base",
+			Strategy: "terraform-root",
+			Path:     ".",
+			Want:     []string{"."},
+		},
+		{
+			Name:     "terraform roots",
+			Strategy: "terraform-root",
+			Path:     "modules",
+			Want:     []string{"network", "storage"},
+		},
+		{
+			Name:     "ansible roles",
+			Strategy: "ansible-role",
+			Path:     ".",
+			Want:     []string{"roles/db", "roles/web"},
+		},
+		{
+			Name:     "directory depth",
+			Strategy: "directory-depth=2",
+			Path:     ".",
+			Want:     []string{"modules/network", "modules/storage", "roles/common", "roles/db", "roles/web"},
+		},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			strategy, err := ParseStrategy(tt.Strategy)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := FindRoots(filepath.Join(base, tt.Path), strategy)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(tt.Want, got) {
+				t.Errorf("got %#v, want %#v", got, tt.Want)
+			}
+		})
+
+	}
+
+}
@@ -0,0 +1,148 @@
+package taxonomy
+
+import (
+	_ "embed"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed taxonomy.yaml
+var embedTaxonomy []byte
+
+// Category is a kind of issue, with the rules of each tool that detect it.
+type Category struct {
+	ID          string              `yaml:"id"`
+	Name        string              `yaml:"name"`
+	Description string              `yaml:"description"`
+	CWE         []int               `yaml:"cwe"`
+	Rules       map[string][]string `yaml:"rules"` // Rule ids, or globs as CKV_SECRET_*, by tool name as found in reports
+}
+
+// Taxonomy is a set of categories shared by every tool.
+type Taxonomy struct {
+	Name        string     `yaml:"name"`
+	Version     string     `yaml:"version"`
+	Description string     `yaml:"description"`
+	Categories  []Category `yaml:"categories"`
+}
+
+func FromYaml(content []byte) (*Taxonomy, error) {
+	var t Taxonomy
+
+	if err := yaml.Unmarshal(content, &t); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateCategories(t.Categories); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// Default returns the taxonomy embedded in infrarun.
+func Default() *Taxonomy {
+	t, err := FromYaml(embedTaxonomy)
+
+	if err != nil {
+		panic(fmt.Errorf("invalid embedded taxonomy: %w", err))
+	}
+
+	return t
+}
+
+func ValidateCategories(categories []Category) error {
+	for _, c := range categories {
+		if c.ID == "" {
+			return fmt.Errorf("taxonomy category without an id")
+		}
+
+		for tool, rules := range c.Rules {
+			for _, rule := range rules {
+				if _, err := path.Match(rule, ""); err != nil {
+					return fmt.Errorf("rule %q of %s in category %s is not a valid pattern", rule, tool, c.ID)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Extend returns a copy of t with the given categories. Categories with the id of an existing one add their CWE
+// ids and rules to it, and replace its name and description if set. Other categories are added at the end.
+func (t *Taxonomy) Extend(categories []Category) *Taxonomy {
+	extended := *t
+	extended.Categories = slices.Clone(t.Categories)
+
+	for _, c := range categories {
+		idx := slices.IndexFunc(extended.Categories, func(existing Category) bool {
+			return existing.ID == c.ID
+		})
+
+		if idx == -1 {
+			extended.Categories = append(extended.Categories, c)
+			continue
+		}
+
+		existing := extended.Categories[idx]
+
+		if c.Name != "" {
+			existing.Name = c.Name
+		}
+
+		if c.Description != "" {
+			existing.Description = c.Description
+		}
+
+		for _, cwe := range c.CWE {
+			if !slices.Contains(existing.CWE, cwe) {
+				existing.CWE = append(slices.Clone(existing.CWE), cwe)
+			}
+		}
+
+		rules := make(map[string][]string)
+
+		for tool, ids := range existing.Rules {
+			rules[tool] = slices.Clone(ids)
+		}
+
+		for tool, ids := range c.Rules {
+			rules[tool] = append(rules[tool], ids...)
+		}
+
+		existing.Rules = rules
+		extended.Categories[idx] = existing
+	}
+
+	return &extended
+}
+
+// Classify returns the categories of the given rule of a tool. Tool names are matched case insensitively.
+func (t *Taxonomy) Classify(tool string, rule string) []Category {
+	categories := make([]Category, 0)
+
+	for _, c := range t.Categories {
+		for name, patterns := range c.Rules {
+			if !strings.EqualFold(name, tool) {
+				continue
+			}
+
+			matches := slices.ContainsFunc(patterns, func(pattern string) bool {
+				matched, _ := path.Match(pattern, rule)
+				return matched
+			})
+
+			if matches {
+				categories = append(categories, c)
+				break
+			}
+		}
+	}
+
+	return categories
+}
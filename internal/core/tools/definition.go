@@ -28,6 +28,9 @@ type toolDefinition struct {
 	Parser              string                         `yaml:"parser"`
 	PathTransformations []pathTransformationDefinition `yaml:"path_transformation"`
 	DefaultOptions      map[string]any                 `yaml:"default_options"`
+	SeverityInclude     string                         `yaml:"severity_include"` // Name of a shared severity map, that Severity is merged into
+	Severity            SeverityMap                    `yaml:"severity"`
+	SuccessCodes        []int                          `yaml:"success_codes"`
 }
 
 func toolFromDefinition(definition toolDefinition) (*Tool, error) {
@@ -37,6 +40,25 @@ func toolFromDefinition(definition toolDefinition) (*Tool, error) {
 		Cmd:           definition.Cmd,
 		InputPath:     definition.InputPath,
 		DefaultValues: definition.DefaultOptions,
+		SuccessCodes:  definition.SuccessCodes,
+	}
+
+	var severity SeverityMap
+
+	if definition.SeverityInclude != "" {
+		shared, err := sharedSeverity(definition.SeverityInclude)
+
+		if err != nil {
+			return nil, err
+		}
+
+		severity = shared
+	}
+
+	t.Severity = severity.Merge(definition.Severity)
+
+	if err := t.Severity.Validate(); err != nil {
+		return nil, err
 	}
 
 	for _, ptDef := range definition.PathTransformations {
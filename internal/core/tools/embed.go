@@ -2,12 +2,33 @@ package tools
 
 import (
 	"embed"
+	"fmt"
 	"io/fs"
+	"path"
+
+	"gopkg.in/yaml.v3"
 )
 
-//go:embed definitions/*.yaml
+//go:embed definitions/*.yaml definitions/severity/*.yaml
 var embedTools embed.FS
 
+// sharedSeverity returns the embedded severity map called name, shared by several tool definitions.
+func sharedSeverity(name string) (SeverityMap, error) {
+	var m SeverityMap
+
+	content, err := embedTools.ReadFile(path.Join("definitions", "severity", name+".yaml"))
+
+	if err != nil {
+		return m, fmt.Errorf("unknown shared severity map: %s", name)
+	}
+
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return m, fmt.Errorf("invalid shared severity map %s: %w", name, err)
+	}
+
+	return m, nil
+}
+
 func GetEmbedToolDefinitions() map[string]Tool {
 	tools := make(map[string]Tool)
 
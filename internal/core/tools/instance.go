@@ -2,7 +2,9 @@ package tools
 
 import (
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/infragov-project/infrarun/internal/core/utils"
@@ -18,6 +20,9 @@ type ToolInstance struct {
 	CaptureStdout       bool // Will ignore OutputPath and OutputFile if true, since it uses stdout
 	Parser              ResultParser
 	PathTransformations []PathTransformation
+	Severity            SeverityMap
+	SuccessCodes        []int
+	Options             map[string]any // Values of the placeholders of Cmd, including the defaults
 }
 
 func (t *Tool) DefaultInstance() (*ToolInstance, error) {
@@ -37,6 +42,9 @@ func (t *Tool) DefaultInstance() (*ToolInstance, error) {
 		CaptureStdout:       t.CaptureStdout,
 		Parser:              t.Parser,
 		PathTransformations: t.pathTransformations,
+		Severity:            t.Severity,
+		SuccessCodes:        t.SuccessCodes,
+		Options:             maps.Clone(t.DefaultValues),
 	}, nil
 }
 
@@ -60,6 +68,9 @@ func (t *Tool) ToInstance(params map[string]any) (*ToolInstance, error) {
 		CaptureStdout:       t.CaptureStdout,
 		Parser:              t.Parser,
 		PathTransformations: t.pathTransformations,
+		Severity:            t.Severity,
+		SuccessCodes:        t.SuccessCodes,
+		Options:             fullParams,
 	}, nil
 }
 
@@ -153,7 +164,8 @@ func isIsolatedPlaceholder(line string) (string, bool) {
 // In short, addDefaults returns a map with the same elements as values, but with the added
 // (if needed) default values present in def.
 func addDefaults(values map[string]any, def map[string]any) map[string]any {
-	result := values
+	result := make(map[string]any, len(values)+len(def))
+	maps.Copy(result, values)
 
 	for k, v := range def {
 		_, ok := result[k]
@@ -165,3 +177,12 @@ func addDefaults(values map[string]any, def map[string]any) map[string]any {
 
 	return result
 }
+
+// Succeeded reports whether exitCode is one of a successful run of the tool.
+func (t ToolInstance) Succeeded(exitCode int) bool {
+	if len(t.SuccessCodes) == 0 {
+		return exitCode == 0
+	}
+
+	return slices.Contains(t.SuccessCodes, exitCode)
+}
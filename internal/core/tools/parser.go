@@ -37,7 +37,7 @@ func parseJsonSARIF(data []byte) (*sarif.Report, error) {
 
 // Temporary parser for KICS, since it doesn't fill the level of each result
 // and instead puts and empty string. Some SARIF consumers consider that
-// invalid SARIF.
+// invalid SARIF. The actual level is then set by the severity map of the tool.
 func kicsParser(data []byte) (*sarif.Report, error) {
 	parsed, err := parseJsonSARIF(data)
 
@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Where the native severity of a result is read from.
+const (
+	SeverityFromRule     = "rule"     // The rule id, for tools with a fixed severity per check
+	SeverityFromProperty = "property" // A property of the result or of its rule
+)
+
+var sarifLevels = []string{"none", "note", "warning", "error"}
+
+// Severity is what a native severity of a tool is normalized to.
+type Severity struct {
+	Level            string  `yaml:"level"`             // SARIF level: none, note, warning or error
+	SecuritySeverity float64 `yaml:"security_severity"` // From 0.1 to 10, left out if 0
+}
+
+// SeverityMap maps the native severities of a tool to a [Severity].
+type SeverityMap struct {
+	From     string              `yaml:"from"`     // SeverityFromRule or SeverityFromProperty
+	Property string              `yaml:"property"` // Name of the property, if From is SeverityFromProperty
+	Levels   map[string]Severity `yaml:"levels"`   // Keys are matched case insensitively, and may be globs such as CKV_SECRET_*
+	// Whether CatchAllSeverity also replaces the levels given by the tool, for tools whose levels carry no meaning
+	OverrideLevels bool `yaml:"override_levels"`
+}
+
+// CatchAllSeverity is the key of the severity of the results that no other key matches. Unlike the other keys, it
+// only applies to results that the tool gave no level, unless the map overrides the levels of the tool.
+const CatchAllSeverity = "*"
+
+func (m SeverityMap) IsEmpty() bool {
+	return len(m.Levels) == 0
+}
+
+func (m SeverityMap) Validate() error {
+	if m.IsEmpty() {
+		return nil
+	}
+
+	if err := m.validateSource(); err != nil {
+		return err
+	}
+
+	return m.validateLevels()
+}
+
+// ValidateOverride validates m as an override of another severity map, so it may leave out the source.
+func (m SeverityMap) ValidateOverride() error {
+	if m.From != "" {
+		if err := m.validateSource(); err != nil {
+			return err
+		}
+	}
+
+	return m.validateLevels()
+}
+
+func (m SeverityMap) validateSource() error {
+	switch m.From {
+	case SeverityFromRule:
+		return nil
+	case SeverityFromProperty:
+		if m.Property == "" {
+			return fmt.Errorf("severity map reading from a property needs the name of the property")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown severity source: %q", m.From)
+	}
+}
+
+func (m SeverityMap) validateLevels() error {
+	for key, severity := range m.Levels {
+		if !slices.Contains(sarifLevels, severity.Level) {
+			return fmt.Errorf("severity %q has an invalid level: %q", key, severity.Level)
+		}
+
+		if severity.SecuritySeverity < 0 || severity.SecuritySeverity > 10 {
+			return fmt.Errorf("severity %q has a security severity out of the 0-10 range", key)
+		}
+
+		if _, err := path.Match(key, ""); err != nil {
+			return fmt.Errorf("severity %q is not a valid pattern", key)
+		}
+	}
+
+	return nil
+}
+
+// Merge returns m with the settings of override. Levels of override replace the ones of m with the same key, and
+// its source replaces the one of m along with OverrideLevels.
+func (m SeverityMap) Merge(override SeverityMap) SeverityMap {
+	merged := SeverityMap{
+		From:           m.From,
+		Property:       m.Property,
+		Levels:         maps.Clone(m.Levels),
+		OverrideLevels: m.OverrideLevels || override.OverrideLevels,
+	}
+
+	if override.From != "" {
+		merged.From = override.From
+		merged.Property = override.Property
+		merged.OverrideLevels = override.OverrideLevels
+	}
+
+	if merged.Levels == nil {
+		merged.Levels = make(map[string]Severity)
+	}
+
+	maps.Copy(merged.Levels, override.Levels)
+
+	return merged
+}
+
+// Lookup returns the severity of the native severity value. Exact keys take precedence over patterns, and
+// longer patterns over shorter ones. The [CatchAllSeverity] key is only used if unset is true, for results that the
+// tool gave no level, or if m overrides the levels of the tool.
+func (m SeverityMap) Lookup(value string, unset bool) (Severity, bool) {
+	unset = unset || m.OverrideLevels
+
+	value = strings.ToLower(value)
+	best := ""
+	found := false
+
+	for key := range m.Levels {
+		pattern := strings.ToLower(key)
+
+		if pattern == value {
+			return m.Levels[key], true
+		}
+
+		if key == CatchAllSeverity && !unset {
+			continue
+		}
+
+		if matched, _ := path.Match(pattern, value); matched && (!found || len(key) > len(best) || len(key) == len(best) && key < best) {
+			best = key
+			found = true
+		}
+	}
+
+	if !found {
+		return Severity{}, false
+	}
+
+	return m.Levels[best], true
+}
@@ -13,6 +13,8 @@ type Tool struct {
 	Parser              ResultParser
 	pathTransformations []PathTransformation
 	DefaultValues       map[string]any
+	Severity            SeverityMap
+	SuccessCodes        []int // Exit codes of a successful run, only 0 if empty
 }
 
 type PathTransformation struct {
@@ -28,12 +30,13 @@ func (pt PathTransformation) Apply(path string) (string, bool) {
 	return path, false
 }
 
-func (t ToolInstance) ApplyPathTransformations(path string) string {
+// TransformPath applies the first path transformation of the tool matching path, reporting whether there was one.
+func (t ToolInstance) TransformPath(path string) (string, bool) {
 	for _, transformation := range t.PathTransformations {
 		if new, matched := transformation.Apply(path); matched {
-			return new
+			return new, true
 		}
 	}
 
-	return path
+	return path, false
 }
@@ -1,11 +1,12 @@
 package tools
 
 import (
+	"reflect"
 	"regexp"
 	"testing"
 )
 
-func TestApplyPathTransformations(t *testing.T) {
+func TestTransformPath(t *testing.T) {
 
 	type Test struct {
 		Name string
@@ -72,7 +73,7 @@ func TestApplyPathTransformations(t *testing.T) {
 				PathTransformations: tt.Pt,
 			}
 
-			got := tool.ApplyPathTransformations(tt.In)
+			got, _ := tool.TransformPath(tt.In)
 
 			if tt.Want != got {
 				t.Errorf("got %#v, want %#v", got, tt.Want)
@@ -132,3 +133,63 @@ func TestApply(t *testing.T) {
 	}
 
 }
+
+func TestSucceeded(t *testing.T) {
+
+	type Test struct {
+		Name     string
+		Codes    []int
+		ExitCode int
+		Want     bool
+	}
+
+	tests := []Test{
+		{Name: "default success", ExitCode: 0, Want: true},
+		{Name: "default failure", ExitCode: 1, Want: false},
+		{Name: "success code", Codes: []int{0, 50}, ExitCode: 50, Want: true},
+		{Name: "other code", Codes: []int{0, 50}, ExitCode: 126, Want: false},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			got := ToolInstance{SuccessCodes: tt.Codes}.Succeeded(tt.ExitCode)
+
+			if tt.Want != got {
+				t.Errorf("got %#v, want %#v", got, tt.Want)
+			}
+		})
+
+	}
+
+}
+
+func TestToInstance(t *testing.T) {
+	tool := Tool{
+		Name:          "GLITCH",
+		Cmd:           []string{"lint", "--tech", "%{tech}"},
+		DefaultValues: map[string]any{"tech": "terraform", "flags": []any{}},
+	}
+
+	params := map[string]any{"tech": "ansible"}
+
+	instance, err := tool.ToInstance(params)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := map[string]any{"tech": "ansible"}; !reflect.DeepEqual(params, want) {
+		t.Errorf("got %#v, want %#v", params, want)
+	}
+
+	instance.Options["tech"] = "chef"
+
+	if params["tech"] != "ansible" {
+		t.Errorf("got %#v, want the options of the instance apart from the parameters", params)
+	}
+
+	if got := instance.Cmd[2]; got != "ansible" {
+		t.Errorf("got %#v, want %#v", got, "ansible")
+	}
+}
@@ -0,0 +1,90 @@
+package writers
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+
+	"github.com/infragov-project/infrarun/internal/core/results"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+// checkstyleSeverity maps a SARIF level to a Checkstyle severity.
+func checkstyleSeverity(level string) string {
+	switch level {
+	case "error", "warning":
+		return level
+	default:
+		return "info"
+	}
+}
+
+// writeCheckstyle writes report as Checkstyle XML, with the findings grouped by file and the paths of files
+// prefixed with opts.PathPrefix. Suppressed findings, findings absent since the baseline and findings without file
+// are left out.
+func writeCheckstyle(w io.Writer, report *sarif.Report, opts Options) error {
+	active := make([]results.Finding, 0)
+
+	for _, f := range results.Findings(report) {
+		if !f.Suppressed && f.BaselineState != results.BaselineAbsent && f.URI != "" {
+			active = append(active, f)
+		}
+	}
+
+	byFile := findingsByFile(active)
+	checkstyle := checkstyleReport{Version: "8.0", Files: make([]checkstyleFile, 0, len(byFile))}
+
+	for _, p := range filePaths(byFile) {
+		file := checkstyleFile{Name: path.Join(opts.PathPrefix, p)}
+
+		for _, f := range byFile[p] {
+			file.Errors = append(file.Errors, checkstyleError{
+				Line:     f.StartLine,
+				Column:   f.StartColumn,
+				Severity: checkstyleSeverity(f.Level),
+				Message:  f.Message,
+				Source:   f.Tool + "." + f.Rule,
+			})
+		}
+
+		checkstyle.Files = append(checkstyle.Files, file)
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(checkstyle)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
@@ -0,0 +1,59 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func TestWriteCheckstyle(t *testing.T) {
+	rep := newTestReport()
+	rep.Runs[0].AddResult(sarif.NewRuleResult("CKV_AWS_8").
+		WithLevel("note").
+		WithMessage(sarif.NewTextMessage("unencrypted volume")).
+		AddLocation(sarif.NewLocationWithPhysicalLocation(
+			sarif.NewPhysicalLocation().
+				WithArtifactLocation(sarif.NewSimpleArtifactLocation("modules/db/main.tf")).
+				WithRegion(sarif.NewSimpleRegion(1, 1).WithStartColumn(5)),
+		)))
+
+	var b bytes.Buffer
+
+	err := writeCheckstyle(&b, rep, Options{PathPrefix: "infra"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded checkstyleReport
+
+	err = xml.Unmarshal(b.Bytes(), &decoded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The finding without file is left out, the others are sorted by line
+	want := []checkstyleError{
+		{Line: 1, Column: 5, Severity: "info", Message: "unencrypted volume", Source: "checkov.CKV_AWS_8"},
+		{Line: 3, Severity: "error", Message: "<script>alert(1)</script>", Source: "checkov.CKV_SECRET_6"},
+	}
+
+	if len(decoded.Files) != 1 || decoded.Files[0].Name != "infra/modules/db/main.tf" {
+		t.Fatalf("got %#v, want one file infra/modules/db/main.tf", decoded.Files)
+	}
+
+	got := decoded.Files[0].Errors
+
+	if len(got) != len(want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %#v, want %#v", got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package writers
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/infragov-project/infrarun/internal/core/results"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+type gitlabLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end,omitempty"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+type gitlabIssue struct {
+	Type        string         `json:"type"`
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	EngineName  string         `json:"engine_name,omitempty"`
+	Location    gitlabLocation `json:"location"`
+}
+
+// gitlabSeverity maps the security severity of f, or its level if it has none, to a Code Quality severity.
+func gitlabSeverity(f results.Finding) string {
+	switch {
+	case f.SecuritySeverity >= 9:
+		return "blocker"
+	case f.SecuritySeverity >= 7:
+		return "critical"
+	case f.SecuritySeverity >= 4:
+		return "major"
+	case f.SecuritySeverity > 0:
+		return "minor"
+	}
+
+	switch f.Level {
+	case "error":
+		return "critical"
+	case "warning":
+		return "major"
+	case "note":
+		return "minor"
+	default:
+		return "info"
+	}
+}
+
+// gitlabFingerprint returns a fingerprint of f that does not change across runs, built from its infrarun
+// fingerprint, which survives the code being moved, if it has one.
+func gitlabFingerprint(f results.Finding) string {
+	key := f.Tool + "\x00" + f.Fingerprint
+
+	if f.Fingerprint == "" {
+		key = fmt.Sprintf("%s\x00%s\x00%s\x00%d\x00%s", f.Tool, f.Rule, f.URI, f.StartLine, f.Message)
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:16])
+}
+
+// writeGitLab writes report as a GitLab Code Quality report, with the paths of files relative to the root of the
+// repository through opts.PathPrefix. Suppressed findings, findings absent since the baseline and findings without
+// file are left out, since Code Quality has no place for them.
+func writeGitLab(w io.Writer, report *sarif.Report, opts Options) error {
+	issues := make([]gitlabIssue, 0)
+	seen := make(map[string]int)
+
+	for _, f := range results.Findings(report) {
+		if f.Suppressed || f.BaselineState == results.BaselineAbsent || f.URI == "" {
+			continue
+		}
+
+		fp := gitlabFingerprint(f)
+
+		// Fingerprints must be unique in a report
+		if n := seen[fp]; n > 0 {
+			seen[fp]++
+			fp = gitlabFingerprint(results.Finding{Tool: f.Tool, Fingerprint: fmt.Sprintf("%s/%d", fp, n)})
+		} else {
+			seen[fp] = 1
+		}
+
+		issue := gitlabIssue{
+			Type:        "issue",
+			Description: cmp.Or(f.Message, f.RuleName, f.Rule),
+			CheckName:   f.Tool + "." + f.Rule,
+			Fingerprint: fp,
+			Severity:    gitlabSeverity(f),
+			EngineName:  f.Tool,
+			Location: gitlabLocation{
+				Path:  path.Join(opts.PathPrefix, f.URI),
+				Lines: gitlabLines{Begin: max(f.StartLine, 1)},
+			},
+		}
+
+		if f.EndLine > f.StartLine {
+			issue.Location.Lines.End = f.EndLine
+		}
+
+		issues = append(issues, issue)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(issues)
+}
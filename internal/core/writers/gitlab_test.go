@@ -0,0 +1,86 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/infragov-project/infrarun/internal/core/results"
+)
+
+func TestGitLabSeverity(t *testing.T) {
+
+	type Test struct {
+		Name    string
+		Finding results.Finding
+		Want    string
+	}
+
+	tests := []Test{
+		{Name: "security severity", Finding: results.Finding{Level: "note", SecuritySeverity: 9.8}, Want: "blocker"},
+		{Name: "high security severity", Finding: results.Finding{Level: "note", SecuritySeverity: 7.5}, Want: "critical"},
+		{Name: "low security severity", Finding: results.Finding{Level: "error", SecuritySeverity: 2}, Want: "minor"},
+		{Name: "error", Finding: results.Finding{Level: "error"}, Want: "critical"},
+		{Name: "warning", Finding: results.Finding{Level: "warning"}, Want: "major"},
+		{Name: "none", Finding: results.Finding{Level: "none"}, Want: "info"},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			got := gitlabSeverity(tt.Finding)
+
+			if got != tt.Want {
+				t.Errorf("got %#v, want %#v", got, tt.Want)
+			}
+		})
+	}
+}
+
+func TestWriteGitLab(t *testing.T) {
+
+	write := func(opts Options) []gitlabIssue {
+		var b bytes.Buffer
+
+		err := writeGitLab(&b, newTestReport(), opts)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var issues []gitlabIssue
+
+		err = json.Unmarshal(b.Bytes(), &issues)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return issues
+	}
+
+	issues := write(Options{PathPrefix: "infra"})
+
+	// The finding without file is left out
+	if len(issues) != 1 {
+		t.Fatalf("got %#v, want 1 issue", issues)
+	}
+
+	want := gitlabIssue{
+		Type:        "issue",
+		Description: "<script>alert(1)</script>",
+		CheckName:   "checkov.CKV_SECRET_6",
+		Fingerprint: issues[0].Fingerprint,
+		Severity:    "critical",
+		EngineName:  "checkov",
+		Location:    gitlabLocation{Path: "infra/modules/db/main.tf", Lines: gitlabLines{Begin: 3}},
+	}
+
+	if issues[0] != want {
+		t.Errorf("got %#v, want %#v", issues[0], want)
+	}
+
+	if again := write(Options{}); again[0].Fingerprint != want.Fingerprint {
+		t.Errorf("got fingerprint %#v, want %#v", again[0].Fingerprint, want.Fingerprint)
+	}
+}
@@ -0,0 +1,74 @@
+package writers
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/infragov-project/infrarun/internal/core/results"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+//go:embed html.tmpl
+var htmlTemplate string
+
+var htmlReport = template.Must(template.New("report").Parse(htmlTemplate))
+
+// htmlFile is a file of the HTML report, with its findings.
+type htmlFile struct {
+	Path     string // Empty for the findings without location
+	Findings []results.Finding
+}
+
+type htmlData struct {
+	Title   string
+	Summary *results.Summary
+	Runs    []runInfo
+	Files   []htmlFile
+	Tools   []string
+	Levels  []string
+	Rules   []string
+}
+
+// sortedKeys returns the keys of set, sorted.
+func sortedKeys(set map[string]bool) []string {
+	return slices.Sorted(maps.Keys(set))
+}
+
+// writeHTML writes report as a single HTML page, without external resources, with its findings grouped by file
+// and filterable by tool, level and rule.
+func writeHTML(w io.Writer, report *sarif.Report, opts Options) error {
+	data := htmlData{
+		Title:   opts.Title,
+		Summary: results.Summarize(report, 0),
+		Runs:    runInfos(report),
+	}
+
+	if data.Title == "" {
+		data.Title = "infrarun report"
+	}
+
+	findings := results.Findings(report)
+	files := findingsByFile(findings)
+	tools := make(map[string]bool)
+	levels := make(map[string]bool)
+	rules := make(map[string]bool)
+
+	for _, f := range findings {
+		tools[f.Tool] = true
+		levels[f.Level] = true
+		rules[f.Rule] = true
+	}
+
+	for _, path := range filePaths(files) {
+		data.Files = append(data.Files, htmlFile{Path: path, Findings: files[path]})
+	}
+
+	data.Tools = sortedKeys(tools)
+	data.Levels = sortedKeys(levels)
+	data.Rules = sortedKeys(rules)
+
+	return htmlReport.Execute(w, data)
+}
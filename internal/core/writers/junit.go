@@ -0,0 +1,218 @@
+package writers
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/infragov-project/infrarun/internal/core/results"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Time       string           `xml:"time,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+// junitLocation returns the path and line of f, or the path alone if it has no line.
+func junitLocation(f results.Finding) string {
+	if f.StartLine > 0 {
+		return fmt.Sprintf("%s:%d", f.URI, f.StartLine)
+	}
+
+	return f.URI
+}
+
+// junitFailure returns the failing test case of findings, findings of the same rule of the same tool.
+func junitFailure(name string, findings []results.Finding) junitTestCase {
+	first := findings[0]
+	lines := make([]string, 0, len(findings))
+
+	for _, f := range findings {
+		lines = append(lines, fmt.Sprintf("%s: [%s] %s", cmp.Or(junitLocation(f), "(no file)"), f.Level, f.Message))
+	}
+
+	message := first.Message
+
+	if len(findings) > 1 {
+		message = fmt.Sprintf("%d findings: %s", len(findings), first.Message)
+	}
+
+	return junitTestCase{
+		Name:      cmp.Or(name, "(no file)"),
+		ClassName: first.Tool + "." + first.Rule,
+		File:      first.URI,
+		Failure: &junitProblem{
+			Message: message,
+			Type:    first.Level,
+			Text:    strings.Join(lines, "\n"),
+		},
+	}
+}
+
+// junitSuite returns the test suite of a run, with findings, the unsuppressed findings of the run.
+func junitSuite(info runInfo, findings []results.Finding, opts Options) junitTestSuite {
+	suite := junitTestSuite{
+		Name:      info.Label(),
+		Time:      fmt.Sprintf("%.3f", info.Duration),
+		Timestamp: info.Start,
+		TestCases: make([]junitTestCase, 0),
+	}
+
+	for _, property := range []junitProperty{
+		{"tool", info.Tool},
+		{"version", info.Version},
+		{"image", info.Image},
+		{"imageDigest", info.ImageDigest},
+		{"commandLine", info.CommandLine},
+	} {
+		if property.Value != "" {
+			suite.Properties = append(suite.Properties, property)
+		}
+	}
+
+	if !info.Successful {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      "execution",
+			ClassName: info.Tool,
+			Error: &junitProblem{
+				Message: cmp.Or(strings.Join(info.Errors, "; "), "execution failed"),
+				Type:    "execution",
+				Text:    strings.Join(info.Errors, "\n"),
+			},
+		})
+	} else {
+		// Passes as long as the tool ran, so that clean runs show up in test dashboards
+		suite.TestCases = append(suite.TestCases, junitTestCase{Name: "scan", ClassName: info.Tool})
+	}
+
+	if opts.PerFinding {
+		for _, f := range findings {
+			suite.TestCases = append(suite.TestCases, junitFailure(junitLocation(f), []results.Finding{f}))
+		}
+	} else {
+		groups := make(map[[2]string][]results.Finding)
+
+		for _, f := range findings {
+			key := [2]string{f.Rule, f.URI}
+			groups[key] = append(groups[key], f)
+		}
+
+		keys := make([][2]string, 0, len(groups))
+
+		for key := range groups {
+			keys = append(keys, key)
+		}
+
+		slices.SortFunc(keys, func(a, b [2]string) int { return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1])) })
+
+		for _, key := range keys {
+			suite.TestCases = append(suite.TestCases, junitFailure(key[1], groups[key]))
+		}
+	}
+
+	for _, c := range suite.TestCases {
+		suite.Tests++
+
+		if c.Failure != nil {
+			suite.Failures++
+		}
+
+		if c.Error != nil {
+			suite.Errors++
+		}
+	}
+
+	return suite
+}
+
+// writeJUnit writes report as JUnit XML, for the test dashboards of CI systems. Each run is a test suite, with an
+// error if the tool failed to run, a passing test case if it ran, and a failing test case for each rule and file
+// with findings, or for each finding if opts.PerFinding is set. Suppressed findings, and findings absent since the
+// baseline, are left out.
+func writeJUnit(w io.Writer, report *sarif.Report, opts Options) error {
+	byRun := make(map[int][]results.Finding)
+
+	for _, f := range results.Findings(report) {
+		if !f.Suppressed && f.BaselineState != results.BaselineAbsent {
+			byRun[f.Run] = append(byRun[f.Run], f)
+		}
+	}
+
+	suites := junitTestSuites{
+		Name:       cmp.Or(opts.Title, "infrarun"),
+		TestSuites: make([]junitTestSuite, 0, len(report.Runs)),
+	}
+
+	duration := 0.0
+
+	for i, info := range runInfos(report) {
+		suite := junitSuite(info, byRun[i], opts)
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		duration += info.Duration
+
+		suites.TestSuites = append(suites.TestSuites, suite)
+	}
+
+	suites.Time = fmt.Sprintf("%.3f", duration)
+
+	_, err := io.WriteString(w, xml.Header)
+
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(suites)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
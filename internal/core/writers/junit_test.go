@@ -0,0 +1,87 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/infragov-project/infrarun/internal/core/results"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func TestWriteJUnit(t *testing.T) {
+
+	newReport := func() *sarif.Report {
+		rep := newTestReport()
+		rep.Runs[0].AddResult(sarif.NewRuleResult("CKV_SECRET_6").
+			WithLevel("error").
+			WithMessage(sarif.NewTextMessage("another secret")).
+			AddLocation(sarif.NewLocationWithPhysicalLocation(
+				sarif.NewPhysicalLocation().
+					WithArtifactLocation(sarif.NewSimpleArtifactLocation("modules/db/main.tf")).
+					WithRegion(sarif.NewSimpleRegion(7, 7)),
+			)))
+
+		failed := results.FailedRunReport("KICS")
+		results.AddExecutionError(failed, errors.New("image not found"))
+
+		clean := results.FailedRunReport("GLITCH")
+		clean.Runs[0].Invocations = nil
+
+		return results.MergeReports([]*sarif.Report{rep, failed, clean})
+	}
+
+	type Suite struct {
+		Name     string `xml:"name,attr"`
+		Tests    int    `xml:"tests,attr"`
+		Failures int    `xml:"failures,attr"`
+		Errors   int    `xml:"errors,attr"`
+	}
+
+	type Test struct {
+		Name       string
+		PerFinding bool
+		Want       []Suite
+	}
+
+	tests := []Test{
+		{Name: "by rule and file", Want: []Suite{{"checkov", 3, 2, 0}, {"KICS", 1, 0, 1}, {"GLITCH", 1, 0, 0}}},
+		{Name: "per finding", PerFinding: true, Want: []Suite{{"checkov", 4, 3, 0}, {"KICS", 1, 0, 1}, {"GLITCH", 1, 0, 0}}},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			var b bytes.Buffer
+
+			err := writeJUnit(&b, newReport(), Options{PerFinding: tt.PerFinding})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var decoded struct {
+				Suites []Suite `xml:"testsuite"`
+			}
+
+			err = xml.Unmarshal(b.Bytes(), &decoded)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(decoded.Suites) != len(tt.Want) {
+				t.Fatalf("got %#v, want %#v", decoded.Suites, tt.Want)
+			}
+
+			for i, want := range tt.Want {
+				if decoded.Suites[i] != want {
+					t.Errorf("got %#v, want %#v", decoded.Suites[i], want)
+				}
+			}
+		})
+
+	}
+
+}
@@ -0,0 +1,285 @@
+package writers
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/infragov-project/infrarun/internal/core/results"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// DefaultMarkdownMaxSize is the size, in bytes, that markdown reports are truncated to by default. It fits in the
+// comments of common code hosts, the smallest limit being 65536 characters.
+const DefaultMarkdownMaxSize = 60000
+
+// Longest message of a finding in markdown reports, in bytes.
+const markdownMaxMessage = 300
+
+var markdownLevels = []string{"error", "warning", "note", "none"}
+
+var markdownEscaper = strings.NewReplacer("<", "&lt;", ">", "&gt;", "\r\n", " ", "\n", " ", "|", "\\|", "*", "\\*", "_", "\\_", "`", "'", "[", "\\[", "]", "\\]")
+
+// markdownText escapes text for markdown, keeping it on a single line of at most limit bytes (no limit if zero).
+func markdownText(text string, limit int) string {
+	if limit > 0 && len(text) > limit {
+		text = strings.ToValidUTF8(text[:limit], "") + "…"
+	}
+
+	return markdownEscaper.Replace(text)
+}
+
+// fileLink returns the URL of the given line of the file at p, relative to the analyzed path, under base, or under
+// the repository of run if base is empty and the run records one. It returns an empty string otherwise. Both are
+// the root of the repository, that the analyzed path is at prefix of. Credentials in the URI of the repository, as
+// in reports merged from other tools, are left out of the links.
+func fileLink(base string, prefix string, run *sarif.Run, p string, line int) string {
+	if base == "" && len(run.VersionControlProvenance) > 0 {
+		vcs := run.VersionControlProvenance[0]
+
+		if vcs.RepositoryURI != nil && vcs.RevisionID != nil {
+			u, err := url.Parse(*vcs.RepositoryURI)
+
+			if err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+				u.User = nil
+				u.RawQuery = ""
+				u.Fragment = ""
+
+				base = strings.TrimSuffix(strings.TrimSuffix(u.String(), "/"), ".git") + "/blob/" + *vcs.RevisionID + "/"
+			}
+		}
+	}
+
+	if base == "" || p == "" {
+		return ""
+	}
+
+	link := strings.TrimSuffix(base, "/") + "/" + path.Join(prefix, p)
+
+	if line > 0 {
+		link += fmt.Sprintf("#L%d", line)
+	}
+
+	return link
+}
+
+// markdownFinding returns the list item of f, a finding of run.
+func markdownFinding(f results.Finding, run *sarif.Run, opts Options) string {
+	location := ""
+
+	if f.URI != "" {
+		location = f.URI
+
+		if f.StartLine > 0 {
+			location += fmt.Sprintf(":%d", f.StartLine)
+		}
+
+		location = "`" + strings.ReplaceAll(location, "`", "'") + "`"
+
+		if link := fileLink(opts.LinkBase, opts.PathPrefix, run, f.URI, f.StartLine); link != "" {
+			location = "[" + location + "](" + strings.ReplaceAll(link, " ", "%20") + ")"
+		}
+
+		location = " " + location
+	}
+
+	rule := "`" + strings.ReplaceAll(f.Rule, "`", "'") + "`"
+
+	if f.HelpURI != "" {
+		rule = "[" + rule + "](" + f.HelpURI + ")"
+	}
+
+	return fmt.Sprintf("- **%s** %s %s%s: %s\n", f.Level, markdownText(f.Tool, 0), rule, location, markdownText(f.Message, markdownMaxMessage))
+}
+
+// markdownSection is a heading with the findings under it, grouped by file.
+type markdownSection struct {
+	Heading  string
+	Findings []results.Finding
+}
+
+// markdownCounts returns the table of the findings of each tool by level.
+func markdownCounts(findings []results.Finding, summary *results.Summary) string {
+	counts := make(map[string]map[string]int)
+	used := make(map[string]bool)
+
+	for _, f := range findings {
+		if counts[f.Tool] == nil {
+			counts[f.Tool] = make(map[string]int)
+		}
+
+		counts[f.Tool][f.Level]++
+		used[f.Level] = true
+	}
+
+	levels := make([]string, 0)
+
+	for _, level := range markdownLevels {
+		if used[level] || level != "none" {
+			levels = append(levels, level)
+		}
+	}
+
+	var b strings.Builder
+
+	b.WriteString("| Tool | Findings | " + strings.Join(levels, " | ") + " |\n")
+	b.WriteString("| --- | ---: |" + strings.Repeat(" ---: |", len(levels)) + "\n")
+
+	for _, c := range summary.ByTool {
+		b.WriteString(fmt.Sprintf("| %s | %d |", markdownText(c.Key, 0), c.Count))
+
+		for _, level := range levels {
+			b.WriteString(fmt.Sprintf(" %d |", counts[c.Key][level]))
+		}
+
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// writeMarkdown writes a compact summary of report in markdown, for pull request comments: the counts of findings
+// by tool and level, the failed runs, and the findings in collapsible sections by file, new ones first if the
+// report was compared with a baseline. The output is truncated to opts.MaxSize bytes, with a note of what was
+// left out.
+func writeMarkdown(w io.Writer, report *sarif.Report, opts Options) error {
+	maxSize := cmp.Or(opts.MaxSize, DefaultMarkdownMaxSize)
+
+	if maxSize < 0 {
+		maxSize = math.MaxInt
+	}
+
+	summary := results.Summarize(report, 0)
+
+	active := make([]results.Finding, 0)
+	baseline := false
+
+	for _, f := range results.Findings(report) {
+		if f.BaselineState != "" {
+			baseline = true
+		}
+
+		if !f.Suppressed && f.BaselineState != results.BaselineAbsent {
+			active = append(active, f)
+		}
+	}
+
+	title := cmp.Or(opts.Title, "infrarun report")
+
+	var b strings.Builder
+
+	b.WriteString("## " + markdownText(title, 0) + "\n\n")
+
+	counts := []string{fmt.Sprintf("**%d findings**", summary.Findings)}
+
+	if baseline {
+		counts = append(counts, fmt.Sprintf("%d new", results.CountByBaselineState(report, results.BaselineNew)), fmt.Sprintf("%d fixed", summary.Absent))
+	}
+
+	counts = append(counts, fmt.Sprintf("%d suppressed", summary.Suppressed))
+
+	if summary.Failed > 0 {
+		counts = append(counts, fmt.Sprintf("**%d failed runs**", summary.Failed))
+	}
+
+	b.WriteString(strings.Join(counts, " · ") + "\n\n")
+
+	if len(active) > 0 {
+		b.WriteString(markdownCounts(active, summary) + "\n")
+	}
+
+	for _, r := range runInfos(report) {
+		if !r.Successful {
+			b.WriteString(fmt.Sprintf("> **%s failed**: %s\n\n", markdownText(r.Label(), 0), markdownText(strings.Join(r.Errors, "; "), markdownMaxMessage)))
+		}
+	}
+
+	sections := []markdownSection{{Heading: "Findings", Findings: active}}
+
+	if baseline {
+		isNew := func(f results.Finding) bool { return f.BaselineState == results.BaselineNew }
+
+		sections = []markdownSection{
+			{Heading: "New findings", Findings: slices.DeleteFunc(slices.Clone(active), func(f results.Finding) bool { return !isNew(f) })},
+			{Heading: "Existing findings", Findings: slices.DeleteFunc(slices.Clone(active), isNew)},
+		}
+	}
+
+	omitted, omittedFiles := 0, 0
+	full := false
+
+	for _, section := range sections {
+		if len(section.Findings) == 0 {
+			continue
+		}
+
+		heading := "### " + section.Heading + "\n\n"
+
+		if full || b.Len()+len(heading) > maxSize {
+			full = true
+		} else {
+			b.WriteString(heading)
+		}
+
+		byFile := findingsByFile(section.Findings)
+
+		for _, path := range filePaths(byFile) {
+			findings := byFile[path]
+
+			name := "(no file)"
+
+			if path != "" {
+				name = "<code>" + html.EscapeString(path) + "</code>"
+			}
+
+			open := fmt.Sprintf("<details><summary>%s (%d)</summary>\n\n", name, len(findings))
+			end := "\n</details>\n\n"
+			written := 0
+
+			// Keep room for the truncation note
+			for _, f := range findings {
+				item := markdownFinding(f, report.Runs[f.Run], opts)
+				size := len(item) + len(end) + 200
+
+				if written == 0 {
+					size += len(open)
+				}
+
+				if full || b.Len()+size > maxSize {
+					full = true
+					break
+				}
+
+				if written == 0 {
+					b.WriteString(open)
+				}
+
+				b.WriteString(item)
+				written++
+			}
+
+			if written > 0 {
+				b.WriteString(end)
+			}
+
+			if written < len(findings) {
+				omitted += len(findings) - written
+				omittedFiles++
+			}
+		}
+	}
+
+	if omitted > 0 {
+		b.WriteString(fmt.Sprintf("_Truncated: %d more findings in %d files are not shown. See the full report for every finding._\n", omitted, omittedFiles))
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
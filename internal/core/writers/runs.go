@@ -0,0 +1,110 @@
+package writers
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/infragov-project/infrarun/internal/core/results"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// runInfo is the outcome of a run of a report, with the metadata of its last invocation.
+type runInfo struct {
+	results.RunSummary
+	Version     string
+	Image       string
+	ImageDigest string
+	CommandLine string
+	Start       string
+	End         string
+	ExitCode    *int
+}
+
+// Label returns the automation id of the run, or the name of its tool if it has none.
+func (r runInfo) Label() string {
+	if r.AutomationID != "" {
+		return r.AutomationID
+	}
+
+	return r.Tool
+}
+
+func propertyString(properties *sarif.PropertyBag, key string) string {
+	if properties == nil {
+		return ""
+	}
+
+	value, ok := properties.Properties[key]
+
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprint(value)
+}
+
+// runInfos returns the runs of report in order, with their number of unsuppressed findings.
+func runInfos(report *sarif.Report) []runInfo {
+	summary := results.Summarize(report, 0)
+	infos := make([]runInfo, 0, len(report.Runs))
+
+	for i, run := range report.Runs {
+		info := runInfo{RunSummary: summary.Runs[i]}
+
+		if run.Tool != nil && run.Tool.Driver != nil && run.Tool.Driver.Version != nil {
+			info.Version = *run.Tool.Driver.Version
+		}
+
+		if len(run.Invocations) > 0 {
+			inv := run.Invocations[len(run.Invocations)-1]
+
+			info.Image = propertyString(inv.Properties, "image")
+			info.ImageDigest = propertyString(inv.Properties, "imageDigest")
+			info.ExitCode = inv.ExitCode
+
+			if inv.CommandLine != nil {
+				info.CommandLine = *inv.CommandLine
+			}
+
+			if inv.StartTimeUtc != nil {
+				info.Start = *inv.StartTimeUtc
+			}
+
+			if inv.EndTimeUtc != nil {
+				info.End = *inv.EndTimeUtc
+			}
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// findingsByFile groups findings by the path of their file, sorted by line.
+func findingsByFile(findings []results.Finding) map[string][]results.Finding {
+	files := make(map[string][]results.Finding)
+
+	for _, f := range findings {
+		files[f.URI] = append(files[f.URI], f)
+	}
+
+	for _, inFile := range files {
+		slices.SortStableFunc(inFile, func(a, b results.Finding) int { return cmp.Compare(a.StartLine, b.StartLine) })
+	}
+
+	return files
+}
+
+// filePaths returns the paths of files sorted, with the findings without file, if any, last.
+func filePaths(files map[string][]results.Finding) []string {
+	paths := slices.Sorted(maps.Keys(files))
+
+	if len(paths) > 0 && paths[0] == "" {
+		paths = append(paths[1:], "")
+	}
+
+	return paths
+}
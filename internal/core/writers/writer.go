@@ -0,0 +1,51 @@
+package writers
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/infragov-project/infrarun/internal/core/results"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// Options change how reports are written by the writers that support them.
+type Options struct {
+	Title      string // Title of the report, for the formats showing one
+	MaxSize    int    // Size in bytes that markdown reports are truncated to, DefaultMarkdownMaxSize if zero, no limit if negative
+	LinkBase   string // URL of the root of the repository at the analyzed revision, for links to files; the repository of the runs if empty
+	PathPrefix string // Path of the analyzed path relative to the root of the repository, for the formats that need it
+	PerFinding bool   // One JUnit test case per finding, instead of one per rule and file
+}
+
+// ReportWriter writes a report in an output format.
+type ReportWriter func(w io.Writer, report *sarif.Report, opts Options) error
+
+var writers = map[string]ReportWriter{
+	"sarif":      writeSARIF,
+	"html":       writeHTML,
+	"markdown":   writeMarkdown,
+	"junit":      writeJUnit,
+	"gitlab":     writeGitLab,
+	"checkstyle": writeCheckstyle,
+}
+
+func GetWriter(name string) (ReportWriter, error) {
+	writer, ok := writers[name]
+
+	if !ok {
+		return nil, fmt.Errorf("unknown output format: %s", name)
+	}
+
+	return writer, nil
+}
+
+// Formats returns the names of the output formats, sorted.
+func Formats() []string {
+	return slices.Sorted(maps.Keys(writers))
+}
+
+func writeSARIF(w io.Writer, report *sarif.Report, opts Options) error {
+	return results.WriteReport(w, report)
+}
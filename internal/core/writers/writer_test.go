@@ -0,0 +1,71 @@
+package writers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/owenrumney/go-sarif/v3/pkg/report"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+func newTestReport() *sarif.Report {
+	run := sarif.NewRunWithInformationURI("checkov", "https://example.com")
+	run.AddResult(sarif.NewRuleResult("CKV_SECRET_6").
+		WithLevel("error").
+		WithMessage(sarif.NewTextMessage("<script>alert(1)</script>")).
+		AddLocation(sarif.NewLocationWithPhysicalLocation(
+			sarif.NewPhysicalLocation().
+				WithArtifactLocation(sarif.NewSimpleArtifactLocation("modules/db/main.tf")).
+				WithRegion(sarif.NewSimpleRegion(3, 3)),
+		)))
+	run.AddResult(sarif.NewRuleResult("CKV_AWS_20").
+		WithLevel("note").
+		WithMessage(sarif.NewTextMessage("public bucket")))
+
+	rep := report.NewV210Report()
+	rep.AddRun(run)
+
+	return rep
+}
+
+func TestWriteHTML(t *testing.T) {
+	var b bytes.Buffer
+
+	err := writeHTML(&b, newTestReport(), Options{Title: "Audit"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	html := b.String()
+
+	type Test struct {
+		Name string
+		Text string
+		Want bool
+	}
+
+	tests := []Test{
+		{Name: "title", Text: "<title>Audit</title>", Want: true},
+		{Name: "file group", Text: "modules/db/main.tf", Want: true},
+		{Name: "escaped message", Text: "&lt;script&gt;alert(1)&lt;/script&gt;", Want: true},
+		{Name: "unescaped message", Text: "<script>alert(1)", Want: false},
+		{Name: "external script", Text: "<script src", Want: false},
+		{Name: "external stylesheet", Text: "<link", Want: false},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := strings.Contains(html, tt.Text); got != tt.Want {
+				t.Errorf("got %#v, want %#v", got, tt.Want)
+			}
+		})
+
+	}
+
+	if strings.Index(html, "modules/db/main.tf") > strings.Index(html, "(no file)") {
+		t.Errorf("findings without file are not last")
+	}
+}
@@ -1,10 +1,49 @@
 package plan
 
 import (
+	"maps"
+	"os"
+
 	"github.com/infragov-project/infrarun/internal/core/engine"
+	"github.com/infragov-project/infrarun/internal/core/split"
 	"github.com/infragov-project/infrarun/pkg/infrarun/tool"
 )
 
+// SymlinkPolicy controls what happens to symbolic links found under the path of a [Run].
+type SymlinkPolicy = engine.SymlinkPolicy
+
+const (
+	// SkipSymlinks leaves every symbolic link out of the input of the tool.
+	SkipSymlinks = engine.SkipSymlinks
+	// FollowSymlinksWithinRoot follows symbolic links that point inside the path of the [Run]. This is the default.
+	FollowSymlinksWithinRoot = engine.FollowSymlinksWithinRoot
+	// FollowAllSymlinks follows every symbolic link, wherever it points to.
+	FollowAllSymlinks = engine.FollowAllSymlinks
+)
+
+// ParseSymlinkPolicy returns the [SymlinkPolicy] with the given name: "skip", "follow-within-root" or "follow-all".
+func ParseSymlinkPolicy(name string) (SymlinkPolicy, error) {
+	return engine.ParseSymlinkPolicy(name)
+}
+
+// An InputPolicy decides which files under the path of a [Run] are handed to its tool.
+// Files bigger than MaxFileSize bytes are left out, unless MaxFileSize is zero. Files with binary
+// content are left out when SkipBinary is set. Named pipes, sockets and devices are never staged.
+// When the path is an archive, Archive limits how much is unpacked from it.
+type InputPolicy = engine.InputPolicy
+
+// ArchiveLimits bound the number of entries and the total unpacked size of an archive given as the path of a [Run].
+// Archives exceeding them make the run fail. Zero values mean no limit.
+type ArchiveLimits = engine.ArchiveLimits
+
+// DefaultInputPolicy returns the [InputPolicy] used by new runs.
+func DefaultInputPolicy() InputPolicy {
+	return engine.DefaultInputPolicy()
+}
+
+// A SkippedFile is a file that matched the glob of a [Run] but was left out of its input, along with the reason.
+type SkippedFile = engine.SkippedFile
+
 type Plan struct {
 	Runs []*Run
 }
@@ -13,6 +52,9 @@ type Run struct {
 	Impl *engine.ToolExecution
 }
 
+// NewRun creates a run of tool over the files under path matching glob, with the given tool options.
+// The path may be a directory or a .tar, .tar.gz, .tgz or .zip archive, which is safely unpacked before the
+// run. In the latter case, file locations in the results are relative to the root of the archive.
 func NewRun(path, glob string, tool *tool.Tool, options map[string]any) (*Run, error) {
 	instance, err := tool.Impl.ToInstance(options)
 
@@ -53,10 +95,143 @@ func NewSimpleRun(path string, tool *tool.Tool) (*Run, error) {
 	return NewRunWithDefaultOptions(path, "**/*", tool)
 }
 
+// A SplitStrategy tells how to find the projects of a monorepo, so that each one is analyzed by its own [Run].
+type SplitStrategy = split.Strategy
+
+// ParseSplitStrategy returns the [SplitStrategy] described by value, which is one of "terraform-root"
+// (topmost directories with Terraform files), "ansible-role" (directories with a tasks/main.yml file)
+// or "directory-depth=N" (every directory N levels below the path).
+func ParseSplitStrategy(value string) (SplitStrategy, error) {
+	return split.ParseStrategy(value)
+}
+
+// FindProjectRoots returns the roots of the projects under path found by strategy, relative to path. If path is
+// an archive, the projects are looked for in its content, unpacked within the default limits.
+func FindProjectRoots(path string, strategy SplitStrategy) ([]string, error) {
+	return FindProjectRootsAt(path, "", DefaultInputPolicy().Archive, strategy)
+}
+
+// FindProjectRootsAt is like [FindProjectRoots], but looks for the projects in the files of path as they are in
+// the git revision ref, if not empty, and unpacks archives within limits.
+func FindProjectRootsAt(path string, ref string, limits ArchiveLimits, strategy SplitStrategy) ([]string, error) {
+	dir, err := engine.UnpackInput(path, ref, limits)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if dir == "" {
+		return split.FindRoots(path, strategy)
+	}
+
+	defer os.RemoveAll(dir)
+
+	return split.FindRoots(dir, strategy)
+}
+
+// NewProjectRuns creates a run of tool, with the given options, for each project under path found by strategy.
+// Each run only analyzes the files of its project, among the ones under path matching glob, but file locations in
+// its results stay relative to path. If options is nil, the default options of the tool are used.
+func NewProjectRuns(path, glob string, strategy SplitStrategy, tool *tool.Tool, options map[string]any) ([]*Run, error) {
+	return NewProjectRunsAt(path, "", DefaultInputPolicy(), glob, strategy, tool, options)
+}
+
+// NewProjectRunsAt is like [NewProjectRuns], but finds the projects in the git revision ref, which the runs then
+// analyze as set by [Run.SetRef], and unpacks archives within the limits of policy, which is set on the runs with
+// [Run.SetInputPolicy].
+func NewProjectRunsAt(path, ref string, policy InputPolicy, glob string, strategy SplitStrategy, tool *tool.Tool, options map[string]any) ([]*Run, error) {
+	roots, err := FindProjectRootsAt(path, ref, policy.Archive, strategy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := NewRootRuns(path, roots, glob, tool, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, run := range runs {
+		run.SetInputPolicy(policy)
+		run.SetRef(ref)
+	}
+
+	return runs, nil
+}
+
+// NewRootRuns creates a run of tool, with the given options, for each of the project roots under path, as found
+// by [FindProjectRoots]. Finding the roots once is enough to create the runs of several tools. The files under path
+// matching glob are staged once for every run with the same input, and each run only sees the ones of its project.
+// If options is nil, the default options of the tool are used.
+func NewRootRuns(path string, roots []string, glob string, tool *tool.Tool, options map[string]any) ([]*Run, error) {
+	runs := make([]*Run, 0, len(roots))
+
+	for _, root := range roots {
+		opts := maps.Clone(options)
+
+		if opts == nil {
+			opts = make(map[string]any)
+		}
+
+		run, err := NewRun(path, glob, tool, opts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		run.Impl.ProjectRoot = root
+
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+// ProjectRoot returns the root of the project analyzed by the run, relative to its path, or an empty string
+// if the run was not created by [NewProjectRuns].
+func (r *Run) ProjectRoot() string {
+	return r.Impl.ProjectRoot
+}
+
 func (p *Plan) AddRun(run *Run) {
 	p.Runs = append(p.Runs, run)
 }
 
+// AutomationID returns the identifier of the run, as "tool/options hash/path", followed by "@ref" for runs of a git
+// ref. It labels the run in the report, and is the same for runs of the same tool with the same options over the
+// same path of a repository, wherever it is checked out.
+func (r *Run) AutomationID() string {
+	return r.Impl.AutomationID()
+}
+
 func (r *Run) ToolName() string {
 	return r.Impl.Tool.Name
 }
+
+// SetInputPolicy changes the [InputPolicy] used to stage the input of the run.
+func (r *Run) SetInputPolicy(policy InputPolicy) {
+	r.Impl.Input = policy
+}
+
+// SetRef makes the run analyze the files of its path as they are in the given git revision (a commit, tag or
+// branch of the repository containing the path), instead of the files in the working copy. The working copy
+// is never modified. An empty ref analyzes the working copy.
+func (r *Run) SetRef(ref string) {
+	r.Impl.Ref = ref
+}
+
+// Ref returns the git revision analyzed by the run, or an empty string if it analyzes the working copy.
+func (r *Run) Ref() string {
+	return r.Impl.Ref
+}
+
+// SetExcludes sets glob patterns, relative to the path of the run, of files and directories left out of its input.
+func (r *Run) SetExcludes(patterns ...string) {
+	r.Impl.Excludes = patterns
+}
+
+// SkippedFiles returns the files that were left out of the input of the run. It is only meaningful after the run executed.
+func (r *Run) SkippedFiles() []SkippedFile {
+	return r.Impl.Skipped
+}
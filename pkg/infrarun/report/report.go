@@ -0,0 +1,371 @@
+// Package report contains the processing steps applied to the [SARIF] reports produced by infrarun.
+// They are also applied by [run.Run] when enabled through its options.
+//
+// [SARIF]: https://sarifweb.azurewebsites.net/
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/infragov-project/infrarun/internal/core/results"
+	"github.com/infragov-project/infrarun/internal/core/taxonomy"
+	"github.com/infragov-project/infrarun/internal/core/tools"
+	"github.com/infragov-project/infrarun/internal/core/writers"
+	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
+)
+
+// DedupMode selects what [Deduplicate] does with duplicated results.
+type DedupMode = results.DedupMode
+
+const (
+	// DedupOff leaves the report untouched.
+	DedupOff = results.DedupOff
+	// DedupReport keeps every result. The primary result of each group of duplicates gets a "corroboratedBy"
+	// property listing the other tools and rules, and each of the other results gets a "duplicateOf" property.
+	DedupReport = results.DedupReport
+	// DedupCollapse keeps only the primary result of each group of duplicates, with a "corroboratedBy" property
+	// and the locations of the removed results as related locations.
+	DedupCollapse = results.DedupCollapse
+)
+
+// ParseDedupMode returns the [DedupMode] with the given name: "off", "report" or "collapse".
+func ParseDedupMode(name string) (DedupMode, error) {
+	return results.ParseDedupMode(name)
+}
+
+// RuleEquivalences tells [Deduplicate] which rules, from the same or different tools, detect the same problem.
+type RuleEquivalences = results.RuleEquivalences
+
+// NewRuleEquivalences builds [RuleEquivalences] from named groups of equivalent rules, each written as "tool:rule",
+// where tool is the name of the tool as found in the report (such as KICS, checkov or GLITCH).
+func NewRuleEquivalences(groups map[string][]string) (RuleEquivalences, error) {
+	return results.NewRuleEquivalences(groups)
+}
+
+// Deduplicate finds results reported at the same file and lines by the same rule, or by rules that are
+// equivalent according to equivalences. The first of each group of duplicates, in report order, is the primary
+// result and records the others as corroborating evidence. The others are handled according to mode.
+func Deduplicate(rep *sarif.Report, mode DedupMode, equivalences RuleEquivalences) {
+	results.Deduplicate(rep, mode, equivalences)
+}
+
+// FingerprintKey is the name of the partial fingerprint that infrarun computes for every result. It is
+// built from the tool, the rule, the path and a hash of the code around the result, so it does not change
+// when the code is moved around.
+const FingerprintKey = results.FingerprintKey
+
+// Values of the baselineState of results, as set by [ApplyBaseline].
+const (
+	BaselineNew       = results.BaselineNew
+	BaselineUnchanged = results.BaselineUnchanged
+	BaselineUpdated   = results.BaselineUpdated
+	BaselineAbsent    = results.BaselineAbsent
+)
+
+// ApplyBaseline compares rep with baseline, a report of a previous run, matching results by their [FingerprintKey]
+// fingerprint. Every result of rep gets a baselineState: new, unchanged, or updated (if its level or message
+// changed). Results of baseline missing from rep are added to it as absent. Results without a fingerprint get one
+// computed from the snippet or message of the result, since the analyzed code is not available.
+func ApplyBaseline(rep *sarif.Report, baseline *sarif.Report) {
+	results.ApplyBaseline(rep, baseline)
+}
+
+// CountByBaselineState returns the number of results of rep with the given baselineState.
+func CountByBaselineState(rep *sarif.Report, state string) int {
+	return results.CountByBaselineState(rep, state)
+}
+
+// Values of the kind of the suppressions added to results.
+const (
+	// SuppressionInSource is the kind of the suppressions of results with an "infrarun:ignore" comment.
+	SuppressionInSource = results.SuppressionInSource
+	// SuppressionExternal is the kind of the suppressions added by [ApplyExternalSuppressions].
+	SuppressionExternal = results.SuppressionExternal
+)
+
+// ExternalSuppression accepts the results of a rule of a tool, in the files matching a path glob, until it expires.
+// If it has a [FingerprintKey] fingerprint, it only accepts the result with that fingerprint.
+type ExternalSuppression = results.ExternalSuppression
+
+// ApplyExternalSuppressions adds an external suppression, with the reason as justification and the owner and
+// expiry date as properties, to every result of rep matched by one of suppressions. Suppressions expired at now
+// are ignored, see [ExpiredSuppressions].
+func ApplyExternalSuppressions(rep *sarif.Report, suppressions []ExternalSuppression, now time.Time) {
+	results.ApplyExternalSuppressions(rep, suppressions, now)
+}
+
+// ExpiredSuppressions returns the suppressions whose last day is over at now.
+func ExpiredSuppressions(suppressions []ExternalSuppression, now time.Time) []ExternalSuppression {
+	return results.ExpiredSuppressions(suppressions, now)
+}
+
+// Severity is the SARIF level and security severity that a native severity of a tool is normalized to.
+type Severity = tools.Severity
+
+// SeverityMap maps the native severities of a tool, read from the rule id or from a property of the results, to
+// a [Severity]. Tools define their severity map in their definition, under the severity key.
+type SeverityMap = tools.SeverityMap
+
+// Where a [SeverityMap] reads the native severity of results from.
+const (
+	SeverityFromRule     = tools.SeverityFromRule
+	SeverityFromProperty = tools.SeverityFromProperty
+)
+
+// SecuritySeverityProperty is the property holding the security severity set by [NormalizeSeverity].
+const SecuritySeverityProperty = results.SecuritySeverityProperty
+
+// NormalizeSeverity sets the level and security severity of the results of rep from their native severity,
+// through m. Results whose native severity is not in m are left untouched.
+func NormalizeSeverity(rep *sarif.Report, m SeverityMap) {
+	results.NormalizeSeverity(rep, m)
+}
+
+// Taxonomy is a set of categories of issues, each with the rules of every tool that detect it, and the CWE ids
+// related to it.
+type Taxonomy = taxonomy.Taxonomy
+
+// TaxonomyCategory is a category of a [Taxonomy].
+type TaxonomyCategory = taxonomy.Category
+
+// CWETaxonomyName is the name of the taxonomy that [AddTaxa] adds the CWE ids of categories to.
+const CWETaxonomyName = results.CWETaxonomyName
+
+// DefaultTaxonomy returns the taxonomy embedded in infrarun, based on the security smells catalog of GLITCH. It can
+// be extended with [Taxonomy.Extend].
+func DefaultTaxonomy() *Taxonomy {
+	return taxonomy.Default()
+}
+
+// AddTaxa classifies the results of rep with t, adding references to their categories and CWE ids to their taxa.
+// The referenced categories and CWE ids are added to the taxonomies of each run.
+func AddTaxa(rep *sarif.Report, t *Taxonomy) {
+	results.AddTaxa(rep, t)
+}
+
+// FailedRun is a run of a report whose tool failed to execute, or whose output could not be parsed.
+type FailedRun = results.FailedRun
+
+// FailedRuns returns the runs of rep with an unsuccessful invocation, with the messages of their errors.
+func FailedRuns(rep *sarif.Report) []FailedRun {
+	return results.FailedRuns(rep)
+}
+
+// SourceReader returns the content of an analyzed file, given its path relative to the analyzed root.
+type SourceReader = results.SourceReader
+
+// DirSourceReader returns a [SourceReader] for the files under dir.
+func DirSourceReader(dir string) SourceReader {
+	return results.DirSourceReader(dir)
+}
+
+// DefaultSnippetContextLines is the number of lines around results included in their context region by default.
+const DefaultSnippetContextLines = results.DefaultSnippetContextLines
+
+// AddSnippets fills the region snippet of the results of rep with the code read with read, and their context
+// region with contextLines lines around it. Snippets set by the tools are kept.
+func AddSnippets(rep *sarif.Report, read SourceReader, contextLines int) {
+	results.AddSnippets(rep, read, contextLines)
+}
+
+// RedactSnippets removes every snippet and context region from the results of rep, and the code that GLITCH
+// reports as the message of its results.
+func RedactSnippets(rep *sarif.Report) {
+	results.RedactSnippets(rep)
+}
+
+// AddArtifactHashes sets the sha-256 hash of the artifacts of rep, adding the files with results missing from
+// the artifacts of their run.
+func AddArtifactHashes(rep *sarif.Report, read SourceReader) {
+	results.AddArtifactHashes(rep, read)
+}
+
+// SrcRootBaseID is the uriBaseId that the file locations of reports produced by infrarun are relative to. It
+// stands for the analyzed path.
+const SrcRootBaseID = results.SrcRootBaseID
+
+// Diff is the comparison of two reports made by [Compare].
+type Diff = results.Diff
+
+// DiffFinding is a finding of either of the reports compared by [Compare], with its baselineState.
+type DiffFinding = results.DiffFinding
+
+// DiffSummary counts the new, fixed, updated and unchanged findings of a rule of a tool.
+type DiffSummary = results.DiffSummary
+
+// Compare compares after with before, two reports produced by infrarun, by their [FingerprintKey] fingerprint.
+// Neither report is modified: the SARIF report of the returned [Diff] is a copy of after with the baselineState
+// of its results set, and the fixed results of before added as absent, as done by [ApplyBaseline].
+func Compare(before *sarif.Report, after *sarif.Report) (*Diff, error) {
+	return results.CompareReports(before, after)
+}
+
+// Filter selects the results kept in a report by [ApplyFilters]. Filters are built with [MinLevel], [Rules],
+// [ExcludeRules], [Tools], [Paths] and [Categories], and composed with [AllOf], [AnyOf] and [Not]. Custom filters
+// set Keep to a function of their own.
+type Filter = results.Filter
+
+// FilteredResultsProperty is the run property counting the results removed by [ApplyFilters], by filter name
+// and in total.
+const FilteredResultsProperty = results.FilteredResultsProperty
+
+// ParseLevel checks that level is a SARIF level: none, note, warning or error.
+func ParseLevel(level string) (string, error) {
+	return results.ParseLevel(level)
+}
+
+// MinLevel keeps the results with a level at least as severe as level. Results without a level get the default
+// level of their rule, or warning.
+func MinLevel(level string) Filter {
+	return results.MinLevel(level)
+}
+
+// Rules keeps the results of the rules matching one of patterns, written as rule id globs such as CKV_AWS_*, or
+// as tool:rule to match the rules of a single tool.
+func Rules(patterns ...string) Filter {
+	return results.Rules(patterns...)
+}
+
+// ExcludeRules removes the results of the rules matching one of patterns, written as for [Rules].
+func ExcludeRules(patterns ...string) Filter {
+	return results.ExcludeRules(patterns...)
+}
+
+// Tools keeps the results of the given tools, matched case insensitively.
+func Tools(names ...string) Filter {
+	return results.Tools(names...)
+}
+
+// Paths keeps the results in files matching one of globs, such as modules/**/*.tf, relative to the analyzed path.
+func Paths(globs ...string) Filter {
+	return results.Paths(globs...)
+}
+
+// Categories keeps the results in one of the given categories of the taxonomy added by [AddTaxa], or related to
+// one of the given CWE ids, written as CWE-798.
+func Categories(ids ...string) Filter {
+	return results.Categories(ids...)
+}
+
+// AllOf keeps the results kept by every one of filters.
+func AllOf(filters ...Filter) Filter {
+	return results.AllOf(filters...)
+}
+
+// AnyOf keeps the results kept by at least one of filters.
+func AnyOf(filters ...Filter) Filter {
+	return results.AnyOf(filters...)
+}
+
+// Not keeps the results removed by f.
+func Not(f Filter) Filter {
+	return results.Not(f)
+}
+
+// ApplyFilters removes from rep the results not kept by every one of filters. The removed results are counted in
+// the [FilteredResultsProperty] property of their run, under the name of the first filter that removed them.
+func ApplyFilters(rep *sarif.Report, filters ...Filter) {
+	results.ApplyFilters(rep, filters...)
+}
+
+// Summary counts the findings of a report by tool, level, rule and file, and lists the duration and outcome of its
+// runs. Suppressed findings, and findings absent since the baseline, are only counted in Suppressed and Absent.
+type Summary = results.Summary
+
+// SummaryCount is the number of findings of a tool, of a level or in a file.
+type SummaryCount = results.SummaryCount
+
+// RuleCount is the number of findings of a rule of a tool.
+type RuleCount = results.RuleCount
+
+// RunSummary is the number of findings, duration and outcome of a run.
+type RunSummary = results.RunSummary
+
+// Summarize returns the [Summary] of rep, with the topFiles files with the most findings (every file if topFiles is
+// negative). Counts are sorted from the highest.
+func Summarize(rep *sarif.Report, topFiles int) *Summary {
+	return results.Summarize(rep, topFiles)
+}
+
+// Parse validates content against the SARIF 2.1.0 schema and parses it. The error lists the schema violations of
+// invalid reports.
+func Parse(content []byte) (*sarif.Report, error) {
+	return results.ParseReport(content)
+}
+
+// WriteSARIF writes rep as indented JSON, leaving out the properties that are not set, so that the output is valid
+// against the SARIF 2.1.0 schema.
+func WriteSARIF(w io.Writer, rep *sarif.Report) error {
+	return results.WriteReport(w, rep)
+}
+
+// Open reads the SARIF report at path with [Parse], mentioning path in errors.
+func Open(path string) (*sarif.Report, error) {
+	content, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rep, err := Parse(content)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return rep, nil
+}
+
+// MergeOptions are the processing steps applied by [Merge].
+type MergeOptions struct {
+	Root         string // Analyzed path that file locations are made relative to, the current directory if empty
+	Dedup        DedupMode
+	Equivalences RuleEquivalences
+	Taxonomy     *Taxonomy // Taxonomy to classify results with, none if nil
+}
+
+// Merge merges reports, produced by infrarun or by tools run elsewhere on the files under the root of opts, into a
+// single report, keeping their order. Absolute and file:// locations under the root are made relative to it
+// against [SrcRootBaseID], and results get a [FingerprintKey] fingerprint, are classified and are deduplicated as
+// done by [run.Run]. The runs of reports are moved to the merged report.
+func Merge(reports []*sarif.Report, opts MergeOptions) (*sarif.Report, error) {
+	root := opts.Root
+
+	if root == "" {
+		root = "."
+	}
+
+	return results.MergeExternalReports(reports, root, opts.Taxonomy, opts.Dedup, opts.Equivalences)
+}
+
+// WriteOptions change how [Write] writes reports, in the formats that support them.
+type WriteOptions = writers.Options
+
+// DefaultMarkdownMaxSize is the size, in bytes, that markdown reports are truncated to unless
+// [WriteOptions].MaxSize is set. It fits in the comments of common code hosts.
+const DefaultMarkdownMaxSize = writers.DefaultMarkdownMaxSize
+
+// OutputFormats returns the names of the formats supported by [Write], such as sarif and html.
+func OutputFormats() []string {
+	return writers.Formats()
+}
+
+// Write writes rep to w in the given output format. The html format is a single page, without external resources,
+// with the findings grouped by file and filterable by tool, level and rule, and the outcome of every run. The
+// markdown format is a compact summary for pull request comments, with new findings first if rep was compared
+// with a baseline, truncated to [WriteOptions].MaxSize bytes. The junit format has a test suite per run, with a
+// failing test case per rule and file with findings, an error if the tool failed, and a passing test case otherwise.
+// The gitlab format is a GitLab Code Quality report, with paths relative to the repository through
+// [WriteOptions].PathPrefix, and the checkstyle format is Checkstyle XML with the findings grouped by file.
+func Write(w io.Writer, rep *sarif.Report, format string, opts WriteOptions) error {
+	write, err := writers.GetWriter(format)
+
+	if err != nil {
+		return err
+	}
+
+	return write(w, rep, opts)
+}
@@ -2,12 +2,14 @@ package run
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/infragov-project/infrarun/internal/core/engine"
 	"github.com/infragov-project/infrarun/internal/core/results"
-	"github.com/infragov-project/infrarun/internal/core/tools"
 	"github.com/infragov-project/infrarun/pkg/infrarun/plan"
+	"github.com/infragov-project/infrarun/pkg/infrarun/report"
 	"github.com/owenrumney/go-sarif/v3/pkg/report/v210/sarif"
 )
 
@@ -25,7 +27,16 @@ type RunObserver interface {
 type Option func(*runConfig)
 
 type runConfig struct {
-	observer RunObserver
+	observer     RunObserver
+	dedupMode    report.DedupMode
+	equivalences report.RuleEquivalences
+	baseline     *sarif.Report
+	suppressions []report.ExternalSuppression
+	severity     map[string]report.SeverityMap
+	taxonomy     *report.Taxonomy
+	snippetLines int
+	redact       bool
+	filters      []report.Filter
 }
 
 func WithObserver(obs RunObserver) Option {
@@ -34,9 +45,75 @@ func WithObserver(obs RunObserver) Option {
 	}
 }
 
+// WithDeduplication makes [Run] deduplicate the results of the final report, as done by [report.Deduplicate].
+func WithDeduplication(mode report.DedupMode, equivalences report.RuleEquivalences) Option {
+	return func(opt *runConfig) {
+		opt.dedupMode = mode
+		opt.equivalences = equivalences
+	}
+}
+
+// WithBaseline makes [Run] compare the final report with baseline, a report of a previous run, as done by
+// [report.ApplyBaseline].
+func WithBaseline(baseline *sarif.Report) Option {
+	return func(opt *runConfig) {
+		opt.baseline = baseline
+	}
+}
+
+// WithSuppressions makes [Run] suppress the results of the final report matched by suppressions, as done by
+// [report.ApplyExternalSuppressions]. Expired suppressions are ignored.
+func WithSuppressions(suppressions []report.ExternalSuppression) Option {
+	return func(opt *runConfig) {
+		opt.suppressions = suppressions
+	}
+}
+
+// WithSeverityOverrides makes [Run] normalize the severity of the results of each tool with the severity map of
+// the tool merged with the one of overrides, keyed by infrarun tool name. See [report.SeverityMap.Merge].
+func WithSeverityOverrides(overrides map[string]report.SeverityMap) Option {
+	return func(opt *runConfig) {
+		opt.severity = overrides
+	}
+}
+
+// WithTaxonomy makes [Run] classify the results of the final report with taxonomy instead of the default one, as
+// done by [report.AddTaxa]. A nil taxonomy leaves the results unclassified.
+func WithTaxonomy(taxonomy *report.Taxonomy) Option {
+	return func(opt *runConfig) {
+		opt.taxonomy = taxonomy
+	}
+}
+
+// WithSnippets sets the number of lines around each result included in its context region, as done by
+// [report.AddSnippets]. A negative number leaves the snippets out.
+func WithSnippets(contextLines int) Option {
+	return func(opt *runConfig) {
+		opt.snippetLines = contextLines
+	}
+}
+
+// WithRedaction keeps the analyzed code out of the report: no snippets are added, and the ones set by the tools
+// are removed, as done by [report.RedactSnippets].
+func WithRedaction() Option {
+	return func(opt *runConfig) {
+		opt.redact = true
+	}
+}
+
+// WithFilters makes [Run] remove the results of the final report not kept by every one of filters, as done by
+// [report.ApplyFilters]. Filters apply last, so the results compared with a baseline are filtered as well.
+func WithFilters(filters ...report.Filter) Option {
+	return func(opt *runConfig) {
+		opt.filters = append(opt.filters, filters...)
+	}
+}
+
 func defaultRunConfig() runConfig {
 	return runConfig{
-		observer: emptyRunObserver{},
+		observer:     emptyRunObserver{},
+		taxonomy:     report.DefaultTaxonomy(),
+		snippetLines: report.DefaultSnippetContextLines,
 	}
 }
 
@@ -56,8 +133,42 @@ func (o emptyRunObserver) OnRunParseFail(run *plan.Run, err error) {}
 
 func (o emptyRunObserver) OnRunCompletion(run *plan.Run, report *sarif.Report) {}
 
+// executeRun executes the tool of run and parses its output.
+func executeRun(ctx context.Context, eng *engine.InfrarunEngine, run *plan.Run, observer RunObserver) (*sarif.Report, error) {
+	observer.OnRunStart(run)
+	content, err := eng.Execute(ctx, run.Impl)
+
+	if err != nil {
+		observer.OnRunFail(run, err)
+		return nil, err
+	}
+
+	observer.OnRunParse(run)
+	rep, err := run.Impl.Tool.Parser(content)
+
+	if err != nil {
+		observer.OnRunParseFail(run, err)
+		return nil, err
+	}
+
+	return rep, nil
+}
+
 // Run returns a [SARIF] report with the outputs of the execution of all tools in toolList when running inside path.
-// In case something fails, it will return a nil report with a non-nil error.
+// In case something fails, it will return a nil report with a non-nil error. Runs of tools that fail to execute, or
+// whose output cannot be parsed, are kept in the report without results, with an unsuccessful invocation holding
+// the error as a notification. They can be listed with [report.FailedRuns]. So are runs of tools exiting with a
+// code other than the success codes of their definition, 0 by default, but with their results.
+//
+// The runs of the report follow the order of the runs of plan, each labelled with the automation id of its run
+// (see [plan.Run.AutomationID]). Each run of the report records the command, image, times and exit code of the tool in its invocations.
+//
+// Results get the snippet of the code they were found at, with some context lines around it, and the files with
+// results get their sha-256 hash. See [WithSnippets] and [WithRedaction].
+//
+// Results on a line with, or right below, an "infrarun:ignore <rule|tool:rule> [reason]" comment are kept in the
+// report with an inSource suppression. The comment syntax depends on the type of file, as in "# infrarun:ignore",
+// "// infrarun:ignore", "-- infrarun:ignore" or "<!-- infrarun:ignore ... -->".
 //
 // RunTools requires a currently running [Docker engine]. These tools will be called in parallel, with the paralelization left to the engine.
 //
@@ -80,6 +191,11 @@ func Run(ctx context.Context, plan plan.Plan, opts ...Option) (*sarif.Report, er
 		return nil, err
 	}
 
+	// Acquire every input up front, so runs sharing the same input reuse a single staged copy of it
+	for _, run := range plan.Runs {
+		eng.Inputs.Acquire(run.Impl)
+	}
+
 	var wg sync.WaitGroup
 
 	for _, run := range plan.Runs {
@@ -88,45 +204,85 @@ func Run(ctx context.Context, plan plan.Plan, opts ...Option) (*sarif.Report, er
 
 		go func() {
 			defer wg.Done()
+			defer eng.Inputs.Release(exec)
 
-			config.observer.OnRunStart(run)
-			content, err := eng.Execute(ctx, exec)
+			rep, err := executeRun(ctx, eng, run, config.observer)
 
 			if err != nil {
+				exec.Err = err
+				rep = results.FailedRunReport(exec.Tool.Name)
+			}
+
+			if err == nil && !exec.Tool.Succeeded(exec.Invocation.ExitCode) {
+				err = fmt.Errorf("%s exited with code %d", exec.Tool.Name, exec.Invocation.ExitCode)
 				exec.Err = err
 				config.observer.OnRunFail(run, err)
-				return
 			}
 
-			config.observer.OnRunParse(run)
-			report, err := exec.Tool.Parser(content)
+			results.AddInvocation(rep, exec.Invocation, err == nil)
 
 			if err != nil {
-				exec.Err = err
-				config.observer.OnRunParseFail(run, err)
-				return
+				results.AddExecutionError(rep, err)
+			}
+
+			if exec.Source.Revision != "" {
+				results.AddVersionControlProvenance(rep, exec.Source.RepositoryURI, exec.Source.Revision, exec.Source.Ref)
+			}
+
+			results.AddAutomationDetails(rep, exec.AutomationID(), exec.Tool.Options)
+
+			if exec.ProjectRoot != "" {
+				results.AddProjectRoot(rep, exec.ProjectRoot)
+			}
+
+			if exec.Source.Archive != "" {
+				results.AddArchiveProvenance(rep, exec.Source.Archive, exec.Source.ArchiveSHA256)
+			}
+
+			results.NormalizeSeverity(rep, exec.Tool.Severity.Merge(config.severity[exec.Tool.Name]))
+
+			read := results.DirSourceReader(exec.InputDir)
+
+			results.ReplaceProjectFilePaths(rep, exec.Tool, exec.ProjectRoot)
+			results.AddFingerprints(rep, read)
+			results.ApplyInlineSuppressions(rep, read)
+			results.AddArtifactHashes(rep, read)
+
+			if !config.redact && config.snippetLines >= 0 {
+				results.AddSnippets(rep, read, config.snippetLines)
 			}
 
-			exec.Report = report
-			config.observer.OnRunCompletion(run, report)
+			exec.Report = rep
+
+			if err == nil {
+				config.observer.OnRunCompletion(run, rep)
+			}
 		}()
 	}
 
 	wg.Wait()
 
-	// TODO: see if this still works now that you can have more than one run per tool
-
-	reports := make(map[*tools.ToolInstance]sarif.Report)
+	reports := make([]*sarif.Report, 0, len(plan.Runs))
 
 	for _, run := range plan.Runs {
-		if run.Impl.Err != nil {
-			continue
-		}
-
-		reports[run.Impl.Tool] = *run.Impl.Report
+		reports = append(reports, run.Impl.Report)
 	}
 
 	finalReport := results.GenerateFinalReport(reports)
 
+	report.AddTaxa(finalReport, config.taxonomy)
+	report.Deduplicate(finalReport, config.dedupMode, config.equivalences)
+	report.ApplyExternalSuppressions(finalReport, config.suppressions, time.Now().UTC())
+
+	if config.baseline != nil {
+		report.ApplyBaseline(finalReport, config.baseline)
+	}
+
+	report.ApplyFilters(finalReport, config.filters...)
+
+	if config.redact {
+		report.RedactSnippets(finalReport)
+	}
+
 	return finalReport, nil
 }